@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/boldandbrad/fffetch/internal/calc"
+	"github.com/boldandbrad/fffetch/internal/pfr"
+	"github.com/boldandbrad/fffetch/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// final data file names: <TEAM>_<YEAR>[_idp|_dst].csv
+var finalFilePattern = regexp.MustCompile(`^([A-Z]+)_(\d{4})(_idp|_dst)?\.csv$`)
+
+// league data file names: league_<YEAR>[_idp|_dst].csv
+var leagueFilePattern = regexp.MustCompile(`^league_(\d{4})(_idp|_dst)?\.csv$`)
+
+// kinds of final data, by file name suffix
+var finalKinds = []string{"", "_idp", "_dst"}
+
+var buildYears []int
+
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build league-wide datasets",
+	Long:  "Combine every team's final data into league-wide datasets for each year and across all years",
+	Run: func(cmd *cobra.Command, args []string) {
+		runBuild()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(buildCmd)
+
+	buildCmd.Flags().IntSliceVarP(&buildYears, "year", "y", []int{}, "Years to build (e.g., 2023, 2024). Defaults to all fetched years")
+}
+
+func runBuild() {
+	if err := util.CreateOutDirs(); err != nil {
+		fmt.Printf("Failed to create output directories: %v\n", err)
+		os.Exit(1)
+	}
+
+	finalFiles, err := findFinalFiles()
+	if err != nil {
+		fmt.Printf("Failed to read final data: %v\n", err)
+		os.Exit(1)
+	}
+
+	yearsToBuild := buildYears
+	if len(yearsToBuild) == 0 {
+		for year := range finalFiles {
+			yearsToBuild = append(yearsToBuild, year)
+		}
+	}
+	slices.Sort(yearsToBuild)
+	if len(yearsToBuild) == 0 {
+		fmt.Println("No final data to build, run fetch first")
+		return
+	}
+
+	for _, year := range yearsToBuild {
+		for _, kind := range finalKinds {
+			if err := buildLeague(year, kind, finalFiles[year][kind]); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+	}
+	if err := buildAllYears(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// rebuildLeagues rebuilds the league data of each year that all teams have
+// final data for
+func rebuildLeagues(years []int) error {
+	finalFiles, err := findFinalFiles()
+	if err != nil {
+		return err
+	}
+
+	rebuilt := false
+	for _, year := range years {
+		for _, kind := range finalKinds {
+			if len(finalFiles[year][kind]) < len(pfr.PFR_TEAM_KEYS) {
+				continue
+			}
+			if err := buildLeague(year, kind, finalFiles[year][kind]); err != nil {
+				return err
+			}
+			rebuilt = true
+		}
+	}
+	if !rebuilt {
+		return nil
+	}
+	return buildAllYears()
+}
+
+// buildAllYears stacks each kind of league data across all years
+func buildAllYears() error {
+	for _, kind := range finalKinds {
+		allFilePath := fmt.Sprintf("output/league/league_all%s.csv", kind)
+		built, err := buildAllYearsKind(kind, allFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to build %s: %w", allFilePath, err)
+		}
+		if built > 0 {
+			fmt.Printf("Built %s from %d years\n", allFilePath, built)
+		}
+	}
+	return nil
+}
+
+// findFinalFiles groups the final data files by year and kind
+func findFinalFiles() (map[int]map[string][]string, error) {
+	entries, err := os.ReadDir("output/final")
+	if err != nil {
+		return nil, err
+	}
+
+	finalFiles := map[int]map[string][]string{}
+	for _, entry := range entries {
+		match := finalFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		year, _ := strconv.Atoi(match[2])
+		if _, exists := finalFiles[year]; !exists {
+			finalFiles[year] = map[string][]string{}
+		}
+		finalFiles[year][match[3]] = append(finalFiles[year][match[3]], filepath.Join("output/final", entry.Name()))
+	}
+	return finalFiles, nil
+}
+
+// buildLeague combines one year's team files of a kind into a ranked league file
+func buildLeague(year int, kind string, filePaths []string) error {
+	if len(filePaths) == 0 {
+		return nil
+	}
+	leagueFilePath := fmt.Sprintf("output/league/league_%d%s.csv", year, kind)
+	if err := buildLeagueFile(filePaths, leagueFilePath); err != nil {
+		return fmt.Errorf("failed to build %s: %w", leagueFilePath, err)
+	}
+	fmt.Printf("Built %s from %d teams\n", leagueFilePath, len(filePaths))
+	return nil
+}
+
+func buildLeagueFile(filePaths []string, leagueFilePath string) error {
+	tables, err := readTables(filePaths)
+	if err != nil {
+		return err
+	}
+
+	leagueTable, err := calc.CalcLeagueTable(tables)
+	if err != nil {
+		return err
+	}
+
+	leagueTable, err = calc.CalcLeagueRanks(leagueTable, calc.PtsHeadersOf(leagueTable.Headers))
+	if err != nil {
+		return err
+	}
+	return writeData(leagueFilePath, leagueTable)
+}
+
+// readTables reads each of the given CSV files
+func readTables(filePaths []string) ([]util.Table, error) {
+	var tables []util.Table
+	for _, filePath := range filePaths {
+		table, err := util.ReadCSVFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// buildAllYearsKind stacks every year's league file of a kind into one file,
+// returning the number of years included
+func buildAllYearsKind(kind string, allFilePath string) (int, error) {
+	entries, err := os.ReadDir("output/league")
+	if err != nil {
+		return 0, err
+	}
+
+	var tables []util.Table
+	for _, entry := range entries {
+		match := leagueFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil || match[2] != kind {
+			continue
+		}
+		table, err := util.ReadCSVFile(filepath.Join("output/league", entry.Name()))
+		if err != nil {
+			return 0, err
+		}
+		tables = append(tables, table)
+	}
+	if len(tables) == 0 {
+		return 0, nil
+	}
+
+	return len(tables), writeData(allFilePath, util.ConcatTables("league_all", tables))
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/boldandbrad/fffetch/internal/calc"
+	"github.com/boldandbrad/fffetch/internal/draft"
+	"github.com/boldandbrad/fffetch/internal/util"
+	"github.com/boldandbrad/fffetch/pkg/tea"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	draftYear      int
+	draftProjected bool
+	draftScoring   string
+	draftType      string
+	draftBench     int
+	draftBudget    int
+	draftBots      []string
+	draftSlot      int
+	draftSeed      uint64
+)
+
+var draftCmd = &cobra.Command{
+	Use:   "draft",
+	Short: "Run a mock draft",
+	Long:  "Run a mock snake or auction draft from the value over replacement rankings against bot teams, optionally picking for one team yourself",
+	Run: func(cmd *cobra.Command, args []string) {
+		runDraft(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(draftCmd)
+
+	draftCmd.Flags().IntVarP(&draftYear, "year", "y", time.Now().Year()-1, "Year to draft from")
+	draftCmd.Flags().BoolVar(&draftProjected, "projected", false, "Draft from projected rather than actual points")
+	draftCmd.Flags().StringVarP(&draftScoring, "scoring", "s", "", "Scoring profile to draft by. Defaults to the first in the data")
+	draftCmd.Flags().IntVar(&rankTeams, "teams", calc.DEFAULT_LEAGUE.Teams, "Teams in the league")
+	draftCmd.Flags().StringToIntVar(&rankSlots, "slots", calc.DEFAULT_LEAGUE.Slots, "Starters per team at each position or flex slot (e.g., QB=1,RB=2,FLEX=1)")
+	draftCmd.Flags().IntVar(&draftBench, "bench", draft.DEFAULT_BENCH, "Bench spots per team")
+	draftCmd.Flags().StringVar(&draftType, "type", "snake", "Draft type: snake or auction. In an auction, nominating a player opens the bidding at $1, so nominators buy their nominee if no one bids more")
+	draftCmd.Flags().IntVar(&draftBudget, "budget", draft.DEFAULT_BUDGET, "Auction budget per team")
+	draftCmd.Flags().StringSliceVar(&draftBots, "bots", []string{"need", "best", "adp"}, "Bot strategies, assigned to teams in turn: best, need or adp")
+	draftCmd.Flags().IntVar(&draftSlot, "slot", 0, "Draft slot to pick for yourself interactively. Defaults to an all bot draft")
+	draftCmd.Flags().Uint64Var(&draftSeed, "seed", 0, "Random seed, for repeatable drafts. Defaults to a random seed")
+}
+
+func runDraft(cmd *cobra.Command) {
+	if err := util.CreateOutDirs(); err != nil {
+		fmt.Printf("Failed to create output directories: %v\n", err)
+		os.Exit(1)
+	}
+
+	league := loadLeague(cmd)
+	if draftSlot < 0 || draftSlot > league.Teams {
+		fmt.Printf("Draft slot must be between 1 and %d\n", league.Teams)
+		os.Exit(1)
+	}
+	if len(draftBots) == 0 {
+		fmt.Println("No bot strategies provided")
+		os.Exit(1)
+	}
+	if draftType != "snake" && draftType != "auction" {
+		fmt.Printf("Invalid draft type: %s\n", draftType)
+		os.Exit(1)
+	}
+
+	table, err := loadRankingSource(draftYear, draftProjected)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	ptsHeader := calc.NamedProfile(draftScoring).PtsHeader()
+	if draftScoring == "" {
+		ptsHeaders := calc.PtsHeadersOf(table.Headers)
+		if len(ptsHeaders) == 0 {
+			fmt.Printf("No scoring profiles in %d data\n", draftYear)
+			os.Exit(1)
+		}
+		ptsHeader = ptsHeaders[0]
+	}
+	rankedTable, err := calc.CalcValues(table, ptsHeader, league, viper.GetFloat64("tier_gap"))
+	if err != nil {
+		fmt.Printf("Failed to rank %d by %s: %v\n", draftYear, ptsHeader, err)
+		os.Exit(1)
+	}
+	players, err := draft.NewPlayers(rankedTable, ptsHeader)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// bots take the slots around the user's, cycling through the strategies
+	var program *tea.DraftProgram
+	var teams []*draft.Team
+	strategyNames := map[*draft.Team]string{}
+	bots := 0
+	for i := range league.Teams {
+		team := &draft.Team{Name: fmt.Sprintf("Team %d", i+1)}
+		if i+1 == draftSlot {
+			program = tea.NewDraftProgram()
+			team.Name = "You"
+			team.Strategy = humanStrategy{program: program, bid: draftType == "auction"}
+			strategyNames[team] = "you"
+		} else {
+			name := draftBots[bots%len(draftBots)]
+			bots++
+			strategy, err := draft.NewStrategy(name)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			team.Strategy = strategy
+			strategyNames[team] = name
+		}
+		teams = append(teams, team)
+	}
+
+	seed := draftSeed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	mock := draft.New(league, draftBench, draftBudget, players, teams, rand.New(rand.NewPCG(seed, seed)))
+	if draftType == "auction" {
+		err = mock.RunAuction()
+	} else {
+		err = mock.RunSnake()
+	}
+	if program != nil {
+		program.Quit()
+	}
+	if errors.Is(err, tea.ErrDraftQuit) {
+		fmt.Println("Draft quit")
+		return
+	} else if err != nil {
+		fmt.Printf("Draft failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	prefix := strings.TrimSuffix(ptsHeader, "_pts")
+	draftFilePath := fmt.Sprintf("output/drafts/draft_%d_%s_%s.csv", draftYear, prefix, draftType)
+	if err := writeOutput(draftFilePath, picksTable(mock, strategyNames)); err != nil {
+		fmt.Printf("Failed to write %s: %v\n", draftFilePath, err)
+		os.Exit(1)
+	}
+
+	// summarize each team's roster, best projected lineup first
+	slices.SortStableFunc(teams, func(i, j *draft.Team) int {
+		return cmp.Compare(mock.ProjectedPoints(j), mock.ProjectedPoints(i))
+	})
+	fmt.Printf("Mock %s draft of %d %s (seed %d)\n\n", draftType, draftYear, prefix, seed)
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "Team\tStrategy\tProjected Pts\tRoster")
+	for _, team := range teams {
+		starters, bench := mock.Lineup(team.Roster)
+		var names []string
+		for _, player := range append(starters, bench...) {
+			names = append(names, fmt.Sprintf("%s (%s)", player.Name, player.Pos))
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%.2f\t%s\n", team.Name, strategyNames[team], mock.ProjectedPoints(team), strings.Join(names, ", "))
+	}
+	writer.Flush()
+	fmt.Printf("\nSaved to %s\n", draftFilePath)
+}
+
+// picksTable lays out every pick of a draft for output
+func picksTable(mock *draft.Draft, strategyNames map[*draft.Team]string) util.Table {
+	table := util.Table{
+		Name:    "draft",
+		Headers: []string{"pick", "round", "team", "strategy", "player", "player_id", "pos", "price", "pts", "vor", "projected_pts"},
+	}
+	for _, pick := range mock.Picks {
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(pick.Number),
+			strconv.Itoa(pick.Round),
+			pick.Team.Name,
+			strategyNames[pick.Team],
+			pick.Player.Name,
+			pick.Player.ID,
+			pick.Player.Pos,
+			strconv.Itoa(pick.Price),
+			fmt.Sprintf("%.2f", pick.Player.Pts),
+			fmt.Sprintf("%.2f", pick.Player.VOR),
+			fmt.Sprintf("%.2f", mock.ProjectedPoints(pick.Team)),
+		})
+	}
+	return table
+}
+
+// humanStrategy asks the user for their picks and bids
+type humanStrategy struct {
+	program *tea.DraftProgram
+	bid     bool
+}
+
+func (h humanStrategy) Pick(d *draft.Draft, team *draft.Team) (*draft.Player, error) {
+	players := d.Draftable(team)
+	var options []string
+	for _, player := range players {
+		options = append(options, formatDraftPlayer(player, h.bid))
+	}
+
+	title := fmt.Sprintf("Round %d: make your pick", len(team.Roster)+1)
+	if h.bid {
+		title = fmt.Sprintf("Nominate a player ($%d left, max bid $%d)", team.Budget, d.MaxAffordable(team))
+	}
+	choice, err := h.program.Ask(tea.DraftPrompt{Title: title, Status: draftStatus(d, team), Options: options})
+	if err != nil {
+		return nil, err
+	}
+	return players[choice], nil
+}
+
+func (h humanStrategy) MaxBid(d *draft.Draft, team *draft.Team, player *draft.Player) (int, error) {
+	title := fmt.Sprintf("Bid on %s ($%d left, max bid $%d)", formatDraftPlayer(player, true), team.Budget, d.MaxAffordable(team))
+	return h.program.Ask(tea.DraftPrompt{Title: title, Status: draftStatus(d, team), Bid: true})
+}
+
+// draftStatus describes the latest picks and the user's roster so far
+func draftStatus(d *draft.Draft, team *draft.Team) []string {
+	var status []string
+	for _, pick := range d.Picks[max(len(d.Picks)-5, 0):] {
+		line := fmt.Sprintf("Pick %d: %s took %s (%s)", pick.Number, pick.Team.Name, pick.Player.Name, pick.Player.Pos)
+		if pick.Price > 0 {
+			line += fmt.Sprintf(" for $%d", pick.Price)
+		}
+		status = append(status, line)
+	}
+	var names []string
+	for _, player := range team.Roster {
+		names = append(names, fmt.Sprintf("%s (%s)", player.Name, player.Pos))
+	}
+	if len(names) == 0 {
+		names = []string{"empty"}
+	}
+	return append(status, "", "Your roster: "+strings.Join(names, ", "))
+}
+
+func formatDraftPlayer(player *draft.Player, withValue bool) string {
+	label := fmt.Sprintf("%-24s %-4s %-7s %7.1f pts %7.1f vor  #%d", player.Name, player.Pos, player.Team, player.Pts, player.VOR, player.Rank)
+	if withValue {
+		label += fmt.Sprintf("  $%d", player.Value)
+	}
+	return label
+}
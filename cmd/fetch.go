@@ -1,132 +1,561 @@
 package cmd
 
 import (
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
-	"math/rand/v2"
+	"net/http"
 	"os"
+	"os/signal"
+	"runtime"
+	"slices"
 	"strconv"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/boldandbrad/fffetch/internal/calc"
+	"github.com/boldandbrad/fffetch/internal/manifest"
 	"github.com/boldandbrad/fffetch/internal/pfr"
 	"github.com/boldandbrad/fffetch/internal/util"
 	"github.com/boldandbrad/fffetch/pkg/tea"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
-	teams []string
-	years []int
-	force bool
-	show  bool
+	teams     []string
+	years     []int
+	force     string
+	show      bool
+	scoring   []string
+	retries   int
+	baseURL   string
+	userAgent string
+	workers   int
+	rate      float64
+
+	forceStage   manifest.Stage
+	taskManifest *manifest.Manifest
 )
 
 var fetchCmd = &cobra.Command{
 	Use:   "fetch",
 	Short: "Fetch fantasy football data",
 	Long:  "Fetch and process fantasy football data from Pro Football Reference",
+	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		runFetch()
+		runFetch(cmd.Context())
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(fetchCmd)
 
-	fetchCmd.Flags().StringSliceVarP(&teams, "team", "t", []string{}, "Teams to fetch (e.g., KC, BUF, PHI). Defaults to all teams")
-	fetchCmd.Flags().IntSliceVarP(&years, "year", "y", []int{}, "Years to fetch (e.g., 2023, 2024). Defaults to previous year")
-	fetchCmd.Flags().BoolVarP(&force, "force", "f", false, "Force re-fetch existing data")
+	fetchCmd.PersistentFlags().StringSliceVarP(&teams, "team", "t", []string{}, "Teams to fetch (e.g., KC, BUF, PHI). Defaults to all teams")
+	fetchCmd.PersistentFlags().IntSliceVarP(&years, "year", "y", []int{}, "Years to fetch (e.g., 2023, 2024). Defaults to previous year")
+	fetchCmd.PersistentFlags().StringVarP(&force, "force", "f", "", "Force redoing finished teams from a stage on: fetched, parsed, calculated or written (e.g., --force=parsed). Defaults to fetched when given without a stage")
+	fetchCmd.PersistentFlags().Lookup("force").NoOptDefVal = string(manifest.StageFetched)
+	fetchCmd.PersistentFlags().StringSliceVarP(&scoring, "scoring", "s", calc.DEFAULT_PROFILE_NAMES, "Scoring profiles to calculate (e.g., std, ppr, or a profile from the config file)")
+	viper.BindPFlag("scoring", fetchCmd.PersistentFlags().Lookup("scoring"))
+	fetchCmd.PersistentFlags().IntVar(&retries, "retries", pfr.DefaultRetryPolicy.MaxRetries, "Times to retry a rate limited or failed request")
+	viper.BindPFlag("retries", fetchCmd.PersistentFlags().Lookup("retries"))
+	fetchCmd.PersistentFlags().StringVar(&baseURL, "base-url", pfr.PFR_BASE_URL, "Base URL to fetch pages from (e.g., a local mirror)")
+	viper.BindPFlag("base_url", fetchCmd.PersistentFlags().Lookup("base-url"))
+	fetchCmd.PersistentFlags().StringVar(&userAgent, "user-agent", pfr.DEFAULT_USER_AGENT, "User agent to send with requests")
+	viper.BindPFlag("user_agent", fetchCmd.PersistentFlags().Lookup("user-agent"))
+	fetchCmd.PersistentFlags().IntVar(&workers, "workers", runtime.NumCPU(), "Teams to process at once. Requests still share one rate limit")
+	viper.BindPFlag("workers", fetchCmd.PersistentFlags().Lookup("workers"))
+	fetchCmd.PersistentFlags().Float64Var(&rate, "rate", pfr.DEFAULT_RATE, "Requests per minute to allow across all workers, or 0 for no limit (e.g., for a local mirror)")
+	viper.BindPFlag("rate", fetchCmd.PersistentFlags().Lookup("rate"))
+	viper.SetDefault("timeout", 30*time.Second)
 }
 
-func runFetch() {
-	forceFetch := force
-
-	util.CreateOutDirs()
+func runFetch(ctx context.Context) {
+	profiles := loadProfiles()
+	fetcher := newFetcher()
+	loadForceStage()
+	loadManifest()
 
-	teamsToFetch := map[string]string{}
-	if len(teams) == 0 {
-		teamsToFetch = pfr.PFR_TEAM_KEYS
-	} else {
-		for _, team := range teams {
-			if key, exists := pfr.PFR_TEAM_KEYS[team]; exists {
-				teamsToFetch[team] = key
-			} else {
-				fmt.Printf("Invalid team: %s\n", team)
-			}
+	failed, canceled := runTasks(ctx, func(ctx context.Context, team string, teamKey string, year int, onRetry pfr.RetryFunc) (bool, error) {
+		stage, pending, err := resumeStage(team, year)
+		if err != nil || !pending {
+			return false, err
 		}
-		if len(teamsToFetch) == 0 {
-			fmt.Println("No valid teams provided")
+		return true, runStages(ctx, fetcher, team, teamKey, year, stage, profiles, onRetry)
+	})
+
+	// keep league-wide ranks current with refetched teams, unless asked to stop
+	if !canceled {
+		if err := rebuildLeagues(resolveYears()); err != nil {
+			fmt.Printf("Failed to rebuild league data: %v\n", err)
 			os.Exit(1)
 		}
 	}
+	exitTasks(failed, canceled)
+}
 
-	yearsToFetch := []int{}
-	if len(years) == 0 {
-		yearsToFetch = append(yearsToFetch, time.Now().Year()-1)
-	} else {
-		yearsToFetch = years
+// exitTasks exits with status 1 if any task failed, or 130, as if
+// interrupted, if the tasks were canceled before they all ran
+func exitTasks(failed bool, canceled bool) {
+	if failed {
+		os.Exit(1)
+	}
+	if canceled {
+		os.Exit(130)
 	}
+}
 
-	totalTasks := len(yearsToFetch) * len(teamsToFetch)
-	if totalTasks == 0 {
-		fmt.Println("No teams or years to fetch")
+// loadForceStage resolves the stage given to --force, if any
+func loadForceStage() {
+	if force == "" {
 		return
 	}
+	stage, err := manifest.ParseStage(force)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	forceStage = stage
+}
 
-	p := tea.NewProgram(totalTasks)
-	p.Start()
+// loadManifest reads the state of every task, for resuming where each left off
+func loadManifest() {
+	if err := util.CreateOutDirs(); err != nil {
+		fmt.Printf("Failed to create output directories: %v\n", err)
+		os.Exit(1)
+	}
+	m, err := manifest.Load(manifest.DEFAULT_MANIFEST_PATH)
+	if err != nil {
+		fmt.Printf("Failed to read manifest: %v\n", err)
+		os.Exit(1)
+	}
+	taskManifest = m
+}
 
-	done := make(chan struct{})
+// taskFunc processes one team's data for a year, reporting whether there was
+// anything to do. Canceling ctx stops its requests, but not its writes.
+type taskFunc func(ctx context.Context, team string, teamKey string, year int, onRetry pfr.RetryFunc) (bool, error)
 
+// teamYear is one team's season to process
+type teamYear struct {
+	Team    string
+	TeamKey string
+	Year    int
+}
+
+// runTasks runs a task for each requested team and year, reporting progress
+// and any failures, and returns whether any task failed and whether the tasks
+// were canceled
+func runTasks(ctx context.Context, task taskFunc) (bool, bool) {
+	if err := util.CreateOutDirs(); err != nil {
+		fmt.Printf("Failed to create output directories: %v\n", err)
+		os.Exit(1)
+	}
+
+	teamsToFetch := resolveTeams()
+	var jobs []teamYear
+	for _, year := range resolveYears() {
+		for team, teamKey := range teamsToFetch {
+			jobs = append(jobs, teamYear{Team: team, TeamKey: teamKey, Year: year})
+		}
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No teams or years to fetch")
+		return false, false
+	}
+	return runTaskList(ctx, jobs, viper.GetInt("workers"), task)
+}
+
+// runTaskList runs a task for each team and year on a pool of workers,
+// reporting progress and any failures, and returns whether any task failed and
+// whether the tasks were canceled. Once ctx is canceled, or the progress
+// display quit, no more tasks start and those running stop at their next
+// request.
+func runTaskList(ctx context.Context, jobs []teamYear, workers int, task taskFunc) (bool, bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// the first interrupt cancels the tasks, letting running ones finish the
+	// file they are writing, a second one exits right away
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	go func() {
-		yearCount := 0
-		teamCount := 0
-		for _, year := range yearsToFetch {
-			yearCount += 1
-			teamCount = 0
-			for team, team_key := range teamsToFetch {
-				teamCount += 1
-
-				fetchFilePath := fmt.Sprintf("output/fetched_pages/%s_%d.html", team, year)
-				_, err := os.Stat(fetchFilePath)
-				if errors.Is(err, os.ErrNotExist) || forceFetch {
-					pageString := pfr.FetchPage(team_key, year)
-					util.WriteFile(fetchFilePath, pageString)
-
-					tables := pfr.ParsePage(fetchFilePath)
-					for _, table := range tables {
-						csvFilePath := fmt.Sprintf("output/parsed_tables/%s_%d_%s.csv", team, year, table.Name)
-						util.WriteCSVFile(csvFilePath, table)
-					}
-
-					mergedTable := util.MergeTables(tables)
-					csvFilePath := fmt.Sprintf("output/parsed_tables/%s_%d_%s.csv", team, year, mergedTable.Name)
-					util.WriteCSVFile(csvFilePath, mergedTable)
-
-					statTable := calc.CalcAdvStats(mergedTable)
-					statTable = calc.CalcFFStats(statTable)
-
-					updatedTable := statTable.AddTeamAndYear(team, strconv.Itoa(year))
-					updatedTable = updatedTable.Sort()
-					prunedTable := updatedTable.PruneColumns()
-					csvFilePath = fmt.Sprintf("output/final/%s_%d.csv", team, year)
-					util.WriteCSVFile(csvFilePath, prunedTable)
-
-					p.Update(tea.TaskResult{Team: team, Year: year, Success: true})
-
-					if yearCount < len(yearsToFetch) || teamCount < len(teamsToFetch) {
-						time.Sleep(time.Millisecond * time.Duration(rand.IntN(2500-2000)+2000))
-					}
-				} else {
-					p.Update(tea.TaskResult{Team: team, Year: year, Success: false})
+		<-ctx.Done()
+		stop()
+	}()
+
+	p := tea.NewProgram(len(jobs), cancel)
+	p.Start()
+
+	jobCh := make(chan teamYear)
+	resultCh := make(chan tea.TaskResult)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				onRetry := func(attempt int, wait time.Duration, err error) {
+					p.Waiting(tea.RetryWait{Team: job.Team, Year: job.Year, Attempt: attempt, Wait: wait, Err: err})
 				}
+				processed, err := task(ctx, job.Team, job.TeamKey, job.Year, onRetry)
+				resultCh <- tea.TaskResult{Team: job.Team, Year: job.Year, Success: processed && err == nil, Err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobCh)
+		for _, job := range jobs {
+			select {
+			case jobCh <- job:
+			case <-ctx.Done():
+				return
 			}
 		}
-		close(done)
+	}()
+	go func() {
+		wg.Wait()
+		close(resultCh)
 	}()
 
-	<-done
+	// tasks stopped by cancellation are neither reported nor finished
+	var completed, failures []tea.TaskResult
+	finished := 0
+	for result := range resultCh {
+		if errors.Is(result.Err, context.Canceled) {
+			continue
+		}
+		finished++
+		switch {
+		case result.Err != nil:
+			failures = append(failures, result)
+		case result.Success:
+			completed = append(completed, result)
+		}
+		p.Update(result)
+	}
 	p.Quit()
 	time.Sleep(100 * time.Millisecond)
+
+	if len(failures) > 0 {
+		sortResults(failures)
+		fmt.Printf("\n%d of %d tasks failed:\n", len(failures), len(jobs))
+		for _, failure := range failures {
+			fmt.Printf("  %s %d: %v\n", failure.Team, failure.Year, failure.Err)
+		}
+	}
+	if finished < len(jobs) {
+		sortResults(completed)
+		fmt.Printf("\nCanceled, %d of %d tasks completed\n", len(completed), len(jobs))
+		for _, result := range completed {
+			fmt.Printf("  %s %d\n", result.Team, result.Year)
+		}
+		return len(failures) > 0, true
+	}
+	return len(failures) > 0, false
+}
+
+// sortResults orders task results by year, then team
+func sortResults(results []tea.TaskResult) {
+	slices.SortFunc(results, func(a, b tea.TaskResult) int {
+		return cmp.Or(cmp.Compare(a.Year, b.Year), cmp.Compare(a.Team, b.Team))
+	})
+}
+
+// loadProfiles resolves the requested scoring profiles from flags and config
+func loadProfiles() []calc.Profile {
+	customProfiles := map[string]calc.Profile{}
+	if err := viper.UnmarshalKey("profiles", &customProfiles); err != nil {
+		fmt.Printf("Invalid scoring profiles: %v\n", err)
+		os.Exit(1)
+	}
+	profiles, err := calc.ResolveProfiles(viper.GetStringSlice("scoring"), customProfiles)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if len(profiles) == 0 {
+		fmt.Println("No scoring profiles provided")
+		os.Exit(1)
+	}
+	return profiles
+}
+
+// resolveTeams maps the requested teams to their Pro Football Reference keys
+func resolveTeams() map[string]string {
+	if len(teams) == 0 {
+		return pfr.PFR_TEAM_KEYS
+	}
+
+	teamsToFetch := map[string]string{}
+	for _, team := range teams {
+		if key, exists := pfr.PFR_TEAM_KEYS[team]; exists {
+			teamsToFetch[team] = key
+		} else {
+			fmt.Printf("Invalid team: %s\n", team)
+		}
+	}
+	if len(teamsToFetch) == 0 {
+		fmt.Println("No valid teams provided")
+		os.Exit(1)
+	}
+	return teamsToFetch
+}
+
+// resolveYears returns the requested years, defaulting to the previous year
+func resolveYears() []int {
+	if len(years) == 0 {
+		return []int{time.Now().Year() - 1}
+	}
+	return years
+}
+
+// newFetcher configures a page fetcher from flags and config
+func newFetcher() *pfr.Fetcher {
+	client := &http.Client{Timeout: viper.GetDuration("timeout")}
+	limiter := pfr.NewTokenBucketLimiter(viper.GetFloat64("rate"))
+	fetcher := pfr.NewFetcher(client, viper.GetString("base_url"), viper.GetString("user_agent"), limiter)
+	fetcher.Retry.MaxRetries = viper.GetInt("retries")
+	return fetcher
+}
+
+// fetchedPagePath is where a team's fetched page for a year is saved
+func fetchedPagePath(team string, year int) string {
+	return fmt.Sprintf("output/fetched_pages/%s_%d.html", team, year)
+}
+
+// resumeStage returns the stage a team's task for a year starts from, the
+// forced stage or else the first one not completed, and whether there is one
+func resumeStage(team string, year int) (manifest.Stage, bool, error) {
+	if err := adoptPage(team, year); err != nil {
+		return "", false, err
+	}
+	stage, pending := taskManifest.Resume(team, year)
+	if forceStage != "" && (!pending || forceStage.Before(stage)) {
+		return forceStage, true, nil
+	}
+	// only the page is kept between stages, so any later unfinished stage is
+	// redone from it
+	if pending && stage != manifest.StageFetched {
+		stage = manifest.StageParsed
+	}
+	return stage, pending, nil
+}
+
+// adoptPage records a saved page as fetched if the manifest doesn't know of
+// it, such as one fetched before there was a manifest, so it isn't fetched
+// again
+func adoptPage(team string, year int) error {
+	fetchFilePath := fetchedPagePath(team, year)
+	if taskManifest.Completed(team, year, manifest.StageFetched) {
+		return nil
+	}
+	if _, err := os.Stat(fetchFilePath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return taskManifest.RecordFiles(team, year, manifest.StageFetched, fetchFilePath)
+}
+
+// runStages processes one team's season from the given stage on. Parsing
+// only reads the saved page, so it's redone when calculating again, while the
+// written stage rewrites the existing final data as is.
+func runStages(ctx context.Context, fetcher *pfr.Fetcher, team string, teamKey string, year int, stage manifest.Stage, profiles []calc.Profile, onRetry pfr.RetryFunc) error {
+	switch stage {
+	case manifest.StageFetched:
+		if err := fetchPage(ctx, fetcher, team, teamKey, year, onRetry); err != nil {
+			return err
+		}
+	case manifest.StageWritten:
+		return rewriteFinal(team, year)
+	}
+	return processPage(team, year, fetchedPagePath(team, year), profiles)
+}
+
+// fetchPage fetches and saves one team's page for a year
+func fetchPage(ctx context.Context, fetcher *pfr.Fetcher, team string, teamKey string, year int, onRetry pfr.RetryFunc) error {
+	pageString, err := fetcher.FetchTeamPage(ctx, teamKey, year, onRetry)
+	if err != nil {
+		return err
+	}
+	fetchFilePath := fetchedPagePath(team, year)
+	if err := util.WriteFile(fetchFilePath, pageString); err != nil {
+		return err
+	}
+	return taskManifest.RecordFiles(team, year, manifest.StageFetched, fetchFilePath)
+}
+
+// pageTables are the tables parsed from a team's page
+type pageTables struct {
+	Merged util.Table
+	IDP    util.Table
+	DST    []util.Table
+}
+
+// finalTable is a kind of a team's final data, with the path it's written to
+type finalTable struct {
+	Path  string
+	Table util.Table
+}
+
+// processPage processes one team's fetched page for a year into its final
+// data, recording each stage in the manifest
+func processPage(team string, year int, fetchFilePath string, profiles []calc.Profile) error {
+	parsed, err := parsePage(team, year, fetchFilePath)
+	if err != nil {
+		return err
+	}
+	finals, err := calculateFinal(team, year, parsed, profiles)
+	if err != nil {
+		return err
+	}
+	return writeFinalTables(team, year, finals)
+}
+
+// parsePage parses a team's page, saving each table it parses
+func parsePage(team string, year int, fetchFilePath string) (pageTables, error) {
+	var parsed pageTables
+	var parsedFiles []string
+	writeParsed := func(table util.Table) error {
+		csvFilePath := fmt.Sprintf("output/parsed_tables/%s_%d_%s.csv", team, year, table.Name)
+		parsedFiles = append(parsedFiles, csvFilePath)
+		return util.WriteCSVFile(csvFilePath, table)
+	}
+
+	tables, err := pfr.ParsePage(fetchFilePath, pfr.PFR_TABLE_IDS)
+	if err != nil {
+		return parsed, err
+	}
+	parsed.Merged = util.MergeTables(tables)
+	idpTables, err := pfr.ParsePage(fetchFilePath, pfr.PFR_IDP_TABLE_IDS)
+	if err != nil {
+		return parsed, err
+	}
+	parsed.IDP = idpTables[0]
+	parsed.DST, err = pfr.ParsePage(fetchFilePath, pfr.PFR_DST_TABLE_IDS)
+	if err != nil {
+		return parsed, err
+	}
+
+	for _, table := range slices.Concat(tables, []util.Table{parsed.Merged}, idpTables, parsed.DST) {
+		if err := writeParsed(table); err != nil {
+			return parsed, err
+		}
+	}
+	return parsed, taskManifest.RecordFiles(team, year, manifest.StageParsed, parsedFiles...)
+}
+
+// calculateFinal calculates each kind of a team's final data from its
+// parsed tables
+func calculateFinal(team string, year int, parsed pageTables, profiles []calc.Profile) ([]finalTable, error) {
+	offense, err := calcOffense(team, year, parsed.Merged, profiles)
+	if err != nil {
+		return nil, err
+	}
+	idp, err := calcIDP(team, year, parsed.IDP, profiles)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := calcDST(team, year, parsed.DST, profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	finals := []finalTable{
+		{Path: fmt.Sprintf("output/final/%s_%d.csv", team, year), Table: offense},
+		{Path: fmt.Sprintf("output/final/%s_%d_idp.csv", team, year), Table: idp},
+		{Path: fmt.Sprintf("output/final/%s_%d_dst.csv", team, year), Table: dst},
+	}
+	// the tables are only kept in memory, so there are no files to verify
+	return finals, taskManifest.RecordFiles(team, year, manifest.StageCalculated)
+}
+
+// writeFinalTables writes each kind of a team's final data
+func writeFinalTables(team string, year int, finals []finalTable) error {
+	var paths []string
+	for _, final := range finals {
+		if err := writeFinal(final.Path, final.Table); err != nil {
+			return err
+		}
+		paths = append(paths, final.Path)
+	}
+	return taskManifest.RecordFiles(team, year, manifest.StageWritten, paths...)
+}
+
+// rewriteFinal writes a team's existing final data again, e.g. to add it to
+// a newly configured database or in new formats
+func rewriteFinal(team string, year int) error {
+	var finals []finalTable
+	for _, kind := range finalKinds {
+		csvFilePath := fmt.Sprintf("output/final/%s_%d%s.csv", team, year, kind)
+		table, err := util.ReadCSVFile(csvFilePath)
+		if err != nil {
+			return fmt.Errorf("no final data to rewrite: %w", err)
+		}
+		finals = append(finals, finalTable{Path: csvFilePath, Table: table})
+	}
+	return writeFinalTables(team, year, finals)
+}
+
+// calcOffense calculates a team's offensive players and kickers from their
+// merged tables
+func calcOffense(team string, year int, mergedTable util.Table, profiles []calc.Profile) (util.Table, error) {
+	statTable, err := calc.CalcAdvStats(mergedTable)
+	if err != nil {
+		return util.Table{}, err
+	}
+	statTable, err = calc.CalcFFStats(statTable, profiles)
+	if err != nil {
+		return util.Table{}, err
+	}
+
+	updatedTable := statTable.AddTeamAndYear(team, strconv.Itoa(year))
+	projTable, projected, err := loadProjections(year)
+	if err != nil {
+		return util.Table{}, err
+	}
+	if projected {
+		updatedTable = calc.ApplyProjections(updatedTable, projTable)
+	}
+	updatedTable, err = updatedTable.Sort(profiles[0].PtsHeader())
+	if err != nil {
+		return util.Table{}, err
+	}
+	updatedTable, err = calc.CalcPosRanks(updatedTable, calc.PtsHeaders(profiles))
+	if err != nil {
+		return util.Table{}, err
+	}
+	return updatedTable.PruneColumns(util.FinalHeaders(calc.OutputHeaders(profiles))), nil
+}
+
+// calcIDP calculates a team's individual defensive players from their table
+func calcIDP(team string, year int, table util.Table, profiles []calc.Profile) (util.Table, error) {
+	idpTable, err := calc.CalcIDPStats(table, profiles)
+	if err != nil {
+		return util.Table{}, err
+	}
+	idpTable = idpTable.AddTeamAndYear(team, strconv.Itoa(year))
+	idpTable, err = idpTable.Sort(profiles[0].PtsHeader())
+	if err != nil {
+		return util.Table{}, err
+	}
+	idpTable, err = calc.CalcPosRanks(idpTable, calc.PtsHeaders(profiles))
+	if err != nil {
+		return util.Table{}, err
+	}
+	return idpTable.PruneColumns(util.FinalIDPHeaders(calc.OutputHeaders(profiles))), nil
+}
+
+// calcDST calculates a team's defense/special teams from its tables
+func calcDST(team string, year int, tables []util.Table, profiles []calc.Profile) (util.Table, error) {
+	dstTable, err := calc.CalcDSTStats(team, tables, profiles)
+	if err != nil {
+		return util.Table{}, err
+	}
+	dstTable = dstTable.AddTeamAndYear(team, strconv.Itoa(year))
+	dstTable, err = calc.CalcPosRanks(dstTable, calc.PtsHeaders(profiles))
+	if err != nil {
+		return util.Table{}, err
+	}
+	return dstTable.PruneColumns(util.FinalDSTHeaders(calc.OutputHeaders(profiles))), nil
 }
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/boldandbrad/fffetch/internal/calc"
+	"github.com/boldandbrad/fffetch/internal/manifest"
+	"github.com/boldandbrad/fffetch/internal/pfr"
+	"github.com/boldandbrad/fffetch/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var gamelogsCmd = &cobra.Command{
+	Use:   "gamelogs",
+	Short: "Fetch weekly player game logs",
+	Long:  "Fetch and process weekly game logs for each player on a team from Pro Football Reference",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runGamelogs(cmd.Context())
+	},
+}
+
+func init() {
+	fetchCmd.AddCommand(gamelogsCmd)
+}
+
+func runGamelogs(ctx context.Context) {
+	profiles := loadProfiles()
+	fetcher := newFetcher()
+	loadForceStage()
+	loadManifest()
+
+	failed, canceled := runTasks(ctx, func(ctx context.Context, team string, teamKey string, year int, onRetry pfr.RetryFunc) (bool, error) {
+		weeklyFilePath := fmt.Sprintf("output/weekly/%s_%d.csv", team, year)
+		_, err := os.Stat(weeklyFilePath)
+		if errors.Is(err, os.ErrNotExist) || forceStage != "" {
+			return true, gamelogsTask(ctx, fetcher, team, teamKey, year, weeklyFilePath, profiles, onRetry)
+		}
+		return false, nil
+	})
+	exitTasks(failed, canceled)
+}
+
+// gamelogsTask fetches and processes the game logs of every player on one
+// team's page for a year
+func gamelogsTask(ctx context.Context, fetcher *pfr.Fetcher, team string, teamKey string, year int, weeklyFilePath string, profiles []calc.Profile, onRetry pfr.RetryFunc) error {
+	// the team page lists the players, so process it first if needed
+	fetchFilePath := fetchedPagePath(team, year)
+	if _, err := os.Stat(fetchFilePath); errors.Is(err, os.ErrNotExist) {
+		if err := runStages(ctx, fetcher, team, teamKey, year, manifest.StageFetched, profiles, onRetry); err != nil {
+			return err
+		}
+	}
+
+	links, err := pfr.ParsePlayerLinks(fetchFilePath, pfr.PFR_TABLE_IDS)
+	if err != nil {
+		return err
+	}
+
+	var gameLogs []util.GameLog
+	for _, link := range links {
+		gamelogFilePath := fmt.Sprintf("output/fetched_pages/gamelogs/%s_%d.html", link.ID, year)
+		_, err := os.Stat(gamelogFilePath)
+		if errors.Is(err, os.ErrNotExist) || forceStage == manifest.StageFetched {
+			pageString, err := fetcher.FetchPage(ctx, link.GameLogPath(year), onRetry)
+			if err != nil {
+				return err
+			}
+			if err := util.WriteFile(gamelogFilePath, pageString); err != nil {
+				return err
+			}
+		}
+
+		table, err := pfr.ParseGameLog(gamelogFilePath)
+		if errors.Is(err, pfr.ErrTableNotFound) {
+			// no games logged for the season
+			continue
+		} else if err != nil {
+			return err
+		}
+		gameLogs = append(gameLogs, util.GameLog{Player: link.Name, PlayerID: link.ID, Pos: link.Pos, Table: table})
+	}
+
+	weeklyTable := util.MergeGameLogs(gameLogs, pfr.TeamAbbr(team, year))
+	if len(weeklyTable.Rows) == 0 {
+		return fmt.Errorf("no games for %s (%s) in %d player game logs", team, pfr.TeamAbbr(team, year), year)
+	}
+	statTable, err := calc.CalcFFStats(weeklyTable, profiles)
+	if err != nil {
+		return err
+	}
+	updatedTable := statTable.AddTeamAndYear(team, strconv.Itoa(year))
+	prunedTable := updatedTable.PruneColumns(util.FinalWeeklyHeaders(calc.PtsHeaders(profiles)))
+	return writeData(weeklyFilePath, prunedTable)
+}
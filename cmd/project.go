@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/boldandbrad/fffetch/internal/calc"
+	"github.com/boldandbrad/fffetch/internal/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var projectYears []int
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Project fantasy football seasons",
+	Long:  "Project each player's stats and fantasy points for a season from the fetched seasons before it",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		viper.BindPFlag("scoring", cmd.Flags().Lookup("scoring"))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		runProject()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(projectCmd)
+
+	projectCmd.Flags().IntSliceVarP(&projectYears, "year", "y", []int{}, "Years to project (e.g., 2025). Defaults to the current year")
+	projectCmd.Flags().StringSliceVarP(&scoring, "scoring", "s", calc.DEFAULT_PROFILE_NAMES, "Scoring profiles to calculate (e.g., std, ppr, or a profile from the config file)")
+}
+
+func runProject() {
+	if err := util.CreateOutDirs(); err != nil {
+		fmt.Printf("Failed to create output directories: %v\n", err)
+		os.Exit(1)
+	}
+
+	profiles := loadProfiles()
+	model := loadProjectionModel()
+	loadManifest()
+
+	finalFiles, err := findFinalFiles()
+	if err != nil {
+		fmt.Printf("Failed to read final data: %v\n", err)
+		os.Exit(1)
+	}
+
+	var seasons []calc.Season
+	for _, year := range slices.Sorted(maps.Keys(finalFiles)) {
+		tables, err := readTables(finalFiles[year][""])
+		if err != nil {
+			fmt.Printf("Failed to read %d final data: %v\n", year, err)
+			os.Exit(1)
+		}
+		season, err := calc.NewSeason(year, tables)
+		if err != nil {
+			fmt.Printf("Failed to combine %d final data: %v\n", year, err)
+			os.Exit(1)
+		}
+		seasons = append(seasons, season)
+	}
+
+	yearsToProject := projectYears
+	if len(yearsToProject) == 0 {
+		yearsToProject = []int{time.Now().Year()}
+	}
+
+	for _, year := range yearsToProject {
+		projTable, err := calc.CalcProjections(seasons, year, profiles, model)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		projFilePath := projectionsFilePath(year)
+		if err := writeData(projFilePath, projTable); err != nil {
+			fmt.Printf("Failed to write %s: %v\n", projFilePath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Projected %d players to %s\n", len(projTable.Rows), projFilePath)
+
+		// fill in projections for a season that has already been fetched
+		for _, filePath := range finalFiles[year][""] {
+			table, err := util.ReadCSVFile(filePath)
+			if err != nil {
+				fmt.Printf("Failed to read %s: %v\n", filePath, err)
+				os.Exit(1)
+			}
+			if err := writeFinal(filePath, calc.ApplyProjections(table, projTable)); err != nil {
+				fmt.Printf("Failed to write %s: %v\n", filePath, err)
+				os.Exit(1)
+			}
+			if err := taskManifest.RefreshFile(filePath); err != nil {
+				fmt.Printf("Failed to update manifest: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if err := rebuildLeagues(yearsToProject); err != nil {
+		fmt.Printf("Failed to rebuild league data: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadProjectionModel reads the projection model from config, falling back to
+// the default model's parameters
+func loadProjectionModel() calc.ProjectionModel {
+	model := calc.DEFAULT_PROJECTION_MODEL
+	model.AgeCurves = maps.Clone(model.AgeCurves)
+	if err := viper.UnmarshalKey("projection", &model); err != nil {
+		fmt.Printf("Invalid projection model: %v\n", err)
+		os.Exit(1)
+	}
+	if len(model.SeasonWeights) == 0 {
+		fmt.Println("No projection season weights provided")
+		os.Exit(1)
+	}
+	return model
+}
+
+func projectionsFilePath(year int) string {
+	return fmt.Sprintf("output/projections/projections_%d.csv", year)
+}
+
+// loadProjections reads a year's projections, if they have been made
+func loadProjections(year int) (util.Table, bool, error) {
+	table, err := util.ReadCSVFile(projectionsFilePath(year))
+	if errors.Is(err, os.ErrNotExist) {
+		return util.Table{}, false, nil
+	}
+	if err != nil {
+		return util.Table{}, false, err
+	}
+	return table, true, nil
+}
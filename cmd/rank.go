@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/boldandbrad/fffetch/internal/calc"
+	"github.com/boldandbrad/fffetch/internal/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	rankYears     []int
+	rankTeams     int
+	rankSlots     map[string]int
+	rankTierGap   float64
+	rankProjected bool
+)
+
+var rankCmd = &cobra.Command{
+	Use:   "rank",
+	Short: "Rank players by value over replacement",
+	Long:  "Rank players for a draft by their points over each position's replacement level, given a league's size and starting lineup",
+	Run: func(cmd *cobra.Command, args []string) {
+		runRank(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rankCmd)
+
+	rankCmd.Flags().IntSliceVarP(&rankYears, "year", "y", []int{}, "Years to rank (e.g., 2023, 2024). Defaults to previous year")
+	rankCmd.Flags().IntVar(&rankTeams, "teams", calc.DEFAULT_LEAGUE.Teams, "Teams in the league")
+	rankCmd.Flags().StringToIntVar(&rankSlots, "slots", calc.DEFAULT_LEAGUE.Slots, "Starters per team at each position or flex slot (e.g., QB=1,RB=2,FLEX=1)")
+	rankCmd.Flags().Float64Var(&rankTierGap, "tier-gap", calc.DEFAULT_TIER_GAP, "Points drop, in standard deviations, that begins a new tier")
+	viper.BindPFlag("tier_gap", rankCmd.Flags().Lookup("tier-gap"))
+	rankCmd.Flags().BoolVar(&rankProjected, "projected", false, "Rank projected rather than actual points")
+}
+
+func runRank(cmd *cobra.Command) {
+	if err := util.CreateOutDirs(); err != nil {
+		fmt.Printf("Failed to create output directories: %v\n", err)
+		os.Exit(1)
+	}
+
+	league := loadLeague(cmd)
+
+	yearsToRank := rankYears
+	if len(yearsToRank) == 0 {
+		yearsToRank = []int{time.Now().Year() - 1}
+	}
+
+	for _, year := range yearsToRank {
+		table, err := loadRankingSource(year, rankProjected)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		for _, ptsHeader := range calc.PtsHeadersOf(table.Headers) {
+			rankedTable, err := calc.CalcValues(table, ptsHeader, league, viper.GetFloat64("tier_gap"))
+			if err != nil {
+				fmt.Printf("Failed to rank %d by %s: %v\n", year, ptsHeader, err)
+				os.Exit(1)
+			}
+			rankFilePath := rankingsFilePath(year, ptsHeader)
+			if err := writeOutput(rankFilePath, rankedTable.PruneColumns(calc.RankingHeaders(ptsHeader))); err != nil {
+				fmt.Printf("Failed to write %s: %v\n", rankFilePath, err)
+				os.Exit(1)
+			}
+			fmt.Printf("Ranked %d players to %s\n", len(rankedTable.Rows), rankFilePath)
+		}
+	}
+}
+
+// loadLeague reads the league's size and lineup from config, overridden by
+// any flags given
+func loadLeague(cmd *cobra.Command) calc.League {
+	league := calc.DEFAULT_LEAGUE.Clone()
+	if viper.IsSet("league") {
+		league.Slots = nil
+		if err := viper.UnmarshalKey("league", &league); err != nil {
+			fmt.Printf("Invalid league: %v\n", err)
+			os.Exit(1)
+		}
+		if league.Slots == nil {
+			league.Slots = calc.DEFAULT_LEAGUE.Clone().Slots
+		}
+	}
+	if cmd.Flags().Changed("teams") {
+		league.Teams = rankTeams
+	}
+	if cmd.Flags().Changed("slots") {
+		league.Slots = rankSlots
+	}
+	if league.Teams <= 0 {
+		fmt.Println("League must have at least one team")
+		os.Exit(1)
+	}
+	return league
+}
+
+// loadRankingSource reads a year's league data of every kind, one row per
+// player, or its projections
+func loadRankingSource(year int, projected bool) (util.Table, error) {
+	if projected {
+		table, exists, err := loadProjections(year)
+		if err != nil {
+			return util.Table{}, err
+		}
+		if !exists {
+			return util.Table{}, fmt.Errorf("no projections for %d, run project first", year)
+		}
+		return table, nil
+	}
+
+	var tables []util.Table
+	for _, kind := range finalKinds {
+		table, err := util.ReadCSVFile(fmt.Sprintf("output/league/league_%d%s.csv", year, kind))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return util.Table{}, err
+		}
+		tables = append(tables, table)
+	}
+	if len(tables) == 0 {
+		return util.Table{}, fmt.Errorf("no league data for %d, run build first", year)
+	}
+	// a defender who returns kicks has both offense and IDP rows, so keep one
+	return util.ConcatTables("league", calc.OnePerPlayer(tables)), nil
+}
+
+func rankingsFilePath(year int, ptsHeader string) string {
+	return fmt.Sprintf("output/rankings/rankings_%d_%s.csv", year, strings.TrimSuffix(ptsHeader, "_pts"))
+}
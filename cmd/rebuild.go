@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+
+	"github.com/boldandbrad/fffetch/internal/calc"
+	"github.com/boldandbrad/fffetch/internal/pfr"
+	"github.com/boldandbrad/fffetch/internal/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// fetched team page file names: <TEAM>_<YEAR>.html
+var fetchedFilePattern = regexp.MustCompile(`^([A-Z]+)_(\d{4})\.html$`)
+
+var rebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Rebuild final data from fetched pages",
+	Long:  "Reprocess the team pages already saved in output/fetched_pages into final data, without fetching anything",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		viper.BindPFlag("scoring", cmd.Flags().Lookup("scoring"))
+		viper.BindPFlag("workers", cmd.Flags().Lookup("workers"))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		runRebuild(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rebuildCmd)
+
+	rebuildCmd.Flags().StringSliceVarP(&teams, "team", "t", []string{}, "Teams to rebuild (e.g., KC, BUF, PHI). Defaults to all fetched teams")
+	rebuildCmd.Flags().IntSliceVarP(&years, "year", "y", []int{}, "Years to rebuild (e.g., 2023, 2024). Defaults to all fetched years")
+	rebuildCmd.Flags().IntVar(&workers, "workers", runtime.NumCPU(), "Pages to process at once")
+	rebuildCmd.Flags().StringSliceVarP(&scoring, "scoring", "s", calc.DEFAULT_PROFILE_NAMES, "Scoring profiles to calculate (e.g., std, ppr, or a profile from the config file)")
+}
+
+func runRebuild(ctx context.Context) {
+	if err := util.CreateOutDirs(); err != nil {
+		fmt.Printf("Failed to create output directories: %v\n", err)
+		os.Exit(1)
+	}
+	profiles := loadProfiles()
+	loadManifest()
+
+	jobs, err := findFetchedPages()
+	if err != nil {
+		fmt.Printf("Failed to read fetched pages: %v\n", err)
+		os.Exit(1)
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No fetched pages to rebuild, run fetch first")
+		return
+	}
+
+	failed, canceled := runTaskList(ctx, jobs, viper.GetInt("workers"), func(ctx context.Context, team string, teamKey string, year int, onRetry pfr.RetryFunc) (bool, error) {
+		if err := adoptPage(team, year); err != nil {
+			return false, err
+		}
+		return true, processPage(team, year, fetchedPagePath(team, year), profiles)
+	})
+
+	if !canceled {
+		rebuiltYears := map[int]bool{}
+		for _, job := range jobs {
+			rebuiltYears[job.Year] = true
+		}
+		if err := rebuildLeagues(slices.Sorted(maps.Keys(rebuiltYears))); err != nil {
+			fmt.Printf("Failed to rebuild league data: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	exitTasks(failed, canceled)
+}
+
+// findFetchedPages lists the fetched team pages matching the requested teams
+// and years
+func findFetchedPages() ([]teamYear, error) {
+	entries, err := os.ReadDir("output/fetched_pages")
+	if err != nil {
+		return nil, err
+	}
+	var teamsToRebuild map[string]string
+	if len(teams) > 0 {
+		teamsToRebuild = resolveTeams()
+	}
+
+	var jobs []teamYear
+	for _, entry := range entries {
+		match := fetchedFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		team := match[1]
+		year, err := strconv.Atoi(match[2])
+		if err != nil {
+			return nil, err
+		}
+		teamKey, known := pfr.PFR_TEAM_KEYS[team]
+		if !known {
+			continue
+		}
+		if teamsToRebuild != nil && teamsToRebuild[team] == "" {
+			continue
+		}
+		if len(years) > 0 && !slices.Contains(years, year) {
+			continue
+		}
+		jobs = append(jobs, teamYear{Team: team, TeamKey: teamKey, Year: year})
+	}
+	return jobs, nil
+}
@@ -1,11 +1,31 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/boldandbrad/fffetch/internal/store"
+	"github.com/boldandbrad/fffetch/internal/util"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+var cfgFile string
+
+// serializes database writes, which tasks can make concurrently
+var storeMu sync.Mutex
+
+// DEFAULT_PARQUET_DIR is where Parquet exports go unless configured otherwise
+var DEFAULT_PARQUET_DIR = "output/parquet"
+
 var rootCmd = &cobra.Command{
 	Use:   "fffetch",
 	Short: "Fantasy Football Data Fetcher",
@@ -13,7 +33,124 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	if err := rootCmd.ExecuteContext(context.Background()); err != nil {
 		os.Exit(1)
 	}
 }
+
+func init() {
+	cobra.OnInitialize(initConfig)
+
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "Config file (defaults to ./fffetch.yaml or ./fffetch.toml)")
+	rootCmd.PersistentFlags().StringSlice("format", []string{"csv"}, "Output formats to write: csv, json or ndjson")
+	viper.BindPFlag("format", rootCmd.PersistentFlags().Lookup("format"))
+	rootCmd.PersistentFlags().String("db", "", "SQLite database to also keep final data in (defaults to "+store.DEFAULT_DB_PATH+" when given without a file)")
+	rootCmd.PersistentFlags().Lookup("db").NoOptDefVal = store.DEFAULT_DB_PATH
+	viper.BindPFlag("db", rootCmd.PersistentFlags().Lookup("db"))
+	rootCmd.PersistentFlags().String("parquet", "", "Directory to also export final data to as Parquet, partitioned by team (defaults to "+DEFAULT_PARQUET_DIR+" when given without a directory)")
+	rootCmd.PersistentFlags().Lookup("parquet").NoOptDefVal = DEFAULT_PARQUET_DIR
+	viper.BindPFlag("parquet", rootCmd.PersistentFlags().Lookup("parquet"))
+}
+
+func initConfig() {
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+	} else {
+		viper.AddConfigPath(".")
+		viper.SetConfigName("fffetch")
+	}
+
+	// a missing default config file is fine, a broken one is not
+	if err := viper.ReadInConfig(); err != nil {
+		var notFoundErr viper.ConfigFileNotFoundError
+		if cfgFile != "" || !errors.As(err, &notFoundErr) {
+			fmt.Printf("Failed to read config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// writeOutput writes a table in each configured output format
+func writeOutput(filePath string, table util.Table) error {
+	writers, err := util.NewWriters(viper.GetStringSlice("format"))
+	if err != nil {
+		return err
+	}
+	return util.WriteTable(filePath, table, writers)
+}
+
+// writeData writes data that other commands read back in each configured
+// output format, always including CSV
+func writeData(filePath string, table util.Table) error {
+	formats := viper.GetStringSlice("format")
+	if !slices.ContainsFunc(formats, func(format string) bool { return strings.EqualFold(format, "csv") }) {
+		formats = append(formats, "csv")
+	}
+	writers, err := util.NewWriters(formats)
+	if err != nil {
+		return err
+	}
+	return util.WriteTable(filePath, table, writers)
+}
+
+// kinds the database stores each kind of final file as, by file name suffix
+var storeKinds = map[string]string{
+	"":     store.KindOffense,
+	"_idp": store.KindIDP,
+	"_dst": store.KindDST,
+}
+
+// writeFinal writes a team's final data, also exporting it to Parquet and
+// upserting it into the database if configured
+func writeFinal(filePath string, table util.Table) error {
+	if err := writeData(filePath, table); err != nil {
+		return err
+	}
+	if parquetDir := viper.GetString("parquet"); parquetDir != "" {
+		if err := writeParquet(parquetDir, filePath, table); err != nil {
+			return err
+		}
+	}
+	dbPath := viper.GetString("db")
+	if dbPath == "" {
+		return nil
+	}
+	match := finalFilePattern.FindStringSubmatch(filepath.Base(filePath))
+	if match == nil {
+		return fmt.Errorf("not a final data file: %s", filePath)
+	}
+	year, err := strconv.Atoi(match[2])
+	if err != nil {
+		return err
+	}
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	db, err := store.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err := db.Replace(match[1], year, storeKinds[match[3]], table); err != nil {
+		return fmt.Errorf("failed to store %s: %w", filePath, err)
+	}
+	return db.Close()
+}
+
+// writeParquet exports a team's final data to a hive style partition of its
+// kind's dataset, e.g. final_idp/team=KC/2024.parquet. The team is left out
+// of the file, since readers take it from the partition.
+func writeParquet(parquetDir string, filePath string, table util.Table) error {
+	match := finalFilePattern.FindStringSubmatch(filepath.Base(filePath))
+	if match == nil {
+		return fmt.Errorf("not a final data file: %s", filePath)
+	}
+	team, year, kind := match[1], match[2], match[3]
+	var headers []string
+	for _, header := range table.Headers {
+		if header != "team" {
+			headers = append(headers, header)
+		}
+	}
+	parquetFilePath := filepath.Join(parquetDir, "final"+kind, "team="+team, year+".parquet")
+	return util.WriteParquetFile(parquetFilePath, table.PruneColumns(headers))
+}
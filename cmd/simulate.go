@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/boldandbrad/fffetch/internal/calc"
+	"github.com/boldandbrad/fffetch/internal/sim"
+	"github.com/boldandbrad/fffetch/internal/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// weekly data file names: <TEAM>_<YEAR>.csv
+var weeklyFilePattern = regexp.MustCompile(`^([A-Z]+)_(\d{4})\.csv$`)
+
+var (
+	simSeasons int
+	simSeed    uint64
+)
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate <league file>",
+	Short: "Simulate fantasy football seasons",
+	Long:  "Run Monte Carlo seasons of a fantasy league from players' weekly points, reporting each team's expected wins, playoff odds and championship odds",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runSimulate(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+
+	simulateCmd.Flags().IntVarP(&simSeasons, "seasons", "n", 10000, "Seasons to simulate")
+	simulateCmd.Flags().Uint64Var(&simSeed, "seed", 0, "Random seed, for repeatable results. Defaults to a random seed")
+}
+
+func runSimulate(leagueFilePath string) {
+	if err := util.CreateOutDirs(); err != nil {
+		fmt.Printf("Failed to create output directories: %v\n", err)
+		os.Exit(1)
+	}
+	if simSeasons <= 0 {
+		fmt.Println("Seasons to simulate must be positive")
+		os.Exit(1)
+	}
+
+	league, err := loadSimLeague(leagueFilePath)
+	if err != nil {
+		fmt.Printf("Failed to read league file: %v\n", err)
+		os.Exit(1)
+	}
+
+	weekly, season, err := loadSimData(league.Year)
+	if err != nil {
+		fmt.Printf("Failed to read %d data: %v\n", league.Year, err)
+		os.Exit(1)
+	}
+	profile := calc.NamedProfile(league.Scoring)
+	players, err := sim.NewPlayers(weekly, season, profile.PtsHeader(), sim.SeasonGames(league.Year))
+	if err != nil {
+		fmt.Printf("Failed to build player distributions: %v\n", err)
+		os.Exit(1)
+	}
+
+	seed := simSeed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	simulator, err := sim.NewSimulator(league, players, rand.New(rand.NewPCG(seed, seed)))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	results := simulator.Run(simSeasons)
+
+	name := strings.TrimSuffix(filepath.Base(leagueFilePath), filepath.Ext(leagueFilePath))
+	simFilePath := fmt.Sprintf("output/simulations/%s_%d.csv", name, league.Year)
+	if err := writeOutput(simFilePath, sim.ResultsTable(results)); err != nil {
+		fmt.Printf("Failed to write %s: %v\n", simFilePath, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Simulated %d %d seasons of %s (seed %d)\n\n", simSeasons, league.Year, name, seed)
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "Team\tWins\tPoints For\tPlayoffs\tChampion")
+	for _, result := range results {
+		fmt.Fprintf(writer, "%s\t%.2f\t%.2f\t%.1f%%\t%.1f%%\n", result.Team, result.Wins, result.PointsFor, result.PlayoffOdds*100, result.ChampionshipOdds*100)
+	}
+	writer.Flush()
+	fmt.Printf("\nSaved to %s\n", simFilePath)
+}
+
+// loadSimLeague reads a league file, defaulting to the previous season and
+// the first configured scoring profile
+func loadSimLeague(leagueFilePath string) (sim.League, error) {
+	v := viper.New()
+	v.SetConfigFile(leagueFilePath)
+	if err := v.ReadInConfig(); err != nil {
+		return sim.League{}, err
+	}
+
+	var league sim.League
+	if err := v.Unmarshal(&league); err != nil {
+		return sim.League{}, err
+	}
+	if league.Year == 0 {
+		league.Year = time.Now().Year() - 1
+	}
+	if league.Scoring == "" {
+		league.Scoring = calc.DEFAULT_PROFILE_NAMES[0]
+		if scoring := viper.GetStringSlice("scoring"); len(scoring) > 0 {
+			league.Scoring = scoring[0]
+		}
+	}
+	return league, nil
+}
+
+// loadSimData reads a year's weekly data and its final data combined across
+// teams
+func loadSimData(year int) (util.Table, util.Table, error) {
+	entries, err := os.ReadDir("output/weekly")
+	if err != nil {
+		return util.Table{}, util.Table{}, err
+	}
+	var weeklyFiles []string
+	for _, entry := range entries {
+		match := weeklyFilePattern.FindStringSubmatch(entry.Name())
+		if match != nil && match[2] == fmt.Sprint(year) {
+			weeklyFiles = append(weeklyFiles, filepath.Join("output/weekly", entry.Name()))
+		}
+	}
+	weeklyTables, err := readTables(weeklyFiles)
+	if err != nil {
+		return util.Table{}, util.Table{}, err
+	}
+
+	finalFiles, err := findFinalFiles()
+	if err != nil {
+		return util.Table{}, util.Table{}, err
+	}
+	// combine each kind on its own, so a defender's return stats aren't taken
+	// for a second team's stint
+	var seasonTables []util.Table
+	for _, kind := range finalKinds {
+		tables, err := readTables(finalFiles[year][kind])
+		if err != nil {
+			return util.Table{}, util.Table{}, err
+		}
+		if len(tables) == 0 {
+			continue
+		}
+		season, err := calc.CalcLeagueTable(tables)
+		if err != nil {
+			return util.Table{}, util.Table{}, err
+		}
+		seasonTables = append(seasonTables, season)
+	}
+	if len(weeklyTables) == 0 && len(seasonTables) == 0 {
+		return util.Table{}, util.Table{}, fmt.Errorf("no data fetched, run fetch gamelogs first")
+	}
+	season := util.ConcatTables("league", calc.OnePerPlayer(seasonTables))
+	return util.ConcatTables("weekly", weeklyTables), season, nil
+}
@@ -2,9 +2,7 @@ package calc
 
 import (
 	"fmt"
-	"log"
 	"slices"
-	"strconv"
 
 	"github.com/boldandbrad/fffetch/internal/util"
 )
@@ -31,7 +29,7 @@ var fieldsToPercent = []string{
 	"fumbles",
 }
 
-func CalcAdvStats(table util.Table) util.Table {
+func CalcAdvStats(table util.Table) (util.Table, error) {
 	tableMap := table.ToMap()
 
 	// add advanced stat headers
@@ -43,18 +41,24 @@ func CalcAdvStats(table util.Table) util.Table {
 	}
 
 	// calculate advanced stats for each player
-	for _, dict := range tableMap.Dicts {
-		for _, field := range fieldsToPercent {
-			adjFieldName := fmt.Sprintf("%s%%", field)
-			teamTotal, err := strconv.ParseFloat(tableMap.FooterDict[field], 32)
-			playerVal, err := strconv.ParseFloat(dict[field], 32)
+	for _, field := range fieldsToPercent {
+		adjFieldName := fmt.Sprintf("%s%%", field)
+		teamTotal, err := statValue(tableMap.FooterDict, field)
+		if err != nil {
+			return util.Table{}, fmt.Errorf("invalid team total %s: %w", field, err)
+		}
+		for _, dict := range tableMap.Dicts {
+			playerVal, err := statValue(dict, field)
 			if err != nil {
-				log.Fatal(err)
+				return util.Table{}, fmt.Errorf("invalid %s for %s: %w", field, dict["player"], err)
+			}
+			percentage := 0.0
+			if teamTotal != 0 {
+				percentage = playerVal / teamTotal
 			}
-			percentage := (playerVal / teamTotal)
 			dict[adjFieldName] = fmt.Sprintf("%.2f%%", percentage*100)
 		}
 	}
 
-	return tableMap.ToTable()
+	return tableMap.ToTable(), nil
 }
@@ -0,0 +1,118 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/boldandbrad/fffetch/internal/util"
+)
+
+// points allowed tiers by the most points allowed in a game
+var pointsAllowedTiers = []struct {
+	field string
+	max   float64
+}{
+	{"pa_0", 0},
+	{"pa_1_6", 6},
+	{"pa_7_13", 13},
+	{"pa_14_20", 20},
+	{"pa_21_27", 27},
+	{"pa_28_34", 34},
+	{"pa_35_plus", math.Inf(1)},
+}
+
+// team defense stats summed from the defense and returns table totals
+var dstTotals = []struct {
+	field   string
+	table   string
+	sources []string
+}{
+	{"sacks", "defense", []string{"sacks"}},
+	{"def_int", "defense", []string{"def_int"}},
+	{"fumbles_rec", "defense", []string{"fumbles_rec"}},
+	{"def_td", "defense", []string{"def_int_td", "fumbles_rec_td"}},
+	{"safety", "defense", []string{"safety_md"}},
+	{"ret_td", "returns", []string{"kick_ret_td", "punt_ret_td"}},
+}
+
+// CalcDSTStats builds a team's defense/special teams row from its schedule,
+// defense and returns tables
+func CalcDSTStats(team string, tables []util.Table, profiles []Profile) (util.Table, error) {
+	tableMaps := map[string]util.TableMap{}
+	for _, table := range tables {
+		tableMaps[table.Name] = table.ToMap()
+	}
+	games := tableMaps["games"]
+	if err := games.Require("week", "pts_def"); err != nil {
+		return util.Table{}, err
+	}
+
+	var dstMap util.TableMap
+	dstMap.Name = "dst"
+	dstMap.Headers = []string{"player", "player_id", "pos", "g", "pts_allowed"}
+	dstMap.FooterDict = map[string]string{}
+	dict := map[string]string{
+		"player":    fmt.Sprintf("%s D/ST", team),
+		"player_id": fmt.Sprintf("%s_DST", team),
+		"pos":       "DST",
+	}
+
+	// tally regular season games by points allowed, skipping bye weeks,
+	// unplayed games and playoff games, whose weeks are named (e.g. Wild Card)
+	// rather than numbered, since the other stats are regular season totals
+	tierCounts := make([]int, len(pointsAllowedTiers))
+	gamesPlayed := 0
+	ptsAllowed := 0.0
+	for _, game := range games.Dicts {
+		if _, err := strconv.Atoi(game["week"]); err != nil || game["pts_def"] == "" {
+			continue
+		}
+		pts, err := statValue(game, "pts_def")
+		if err != nil {
+			return util.Table{}, fmt.Errorf("invalid pts_def in week %s: %w", game["week"], err)
+		}
+		gamesPlayed += 1
+		ptsAllowed += pts
+		for i, tier := range pointsAllowedTiers {
+			if pts <= tier.max {
+				tierCounts[i] += 1
+				break
+			}
+		}
+	}
+	dict["g"] = strconv.Itoa(gamesPlayed)
+	dict["pts_allowed"] = formatStat(ptsAllowed)
+	for i, tier := range pointsAllowedTiers {
+		dstMap.Headers = append(dstMap.Headers, tier.field)
+		dict[tier.field] = strconv.Itoa(tierCounts[i])
+	}
+
+	// sum season totals from table footers
+	for _, total := range dstTotals {
+		tableMap, exists := tableMaps[total.table]
+		if !exists {
+			return util.Table{}, &util.MissingColumnError{Table: total.table, Column: total.sources[0]}
+		}
+		sum := 0.0
+		for _, source := range total.sources {
+			val, err := statValue(tableMap.FooterDict, source)
+			if err != nil {
+				return util.Table{}, fmt.Errorf("invalid team total %s: %w", source, err)
+			}
+			sum += val
+		}
+		dstMap.Headers = append(dstMap.Headers, total.field)
+		dict[total.field] = formatStat(sum)
+	}
+
+	dstMap.Dicts = []map[string]string{dict}
+	if err := addScores(&dstMap, profiles, Profile.ScoreDST); err != nil {
+		return util.Table{}, err
+	}
+	return dstMap.ToTable(), nil
+}
+
+func formatStat(val float64) string {
+	return strconv.FormatFloat(val, 'f', -1, 64)
+}
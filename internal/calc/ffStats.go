@@ -2,7 +2,6 @@ package calc
 
 import (
 	"fmt"
-	"log"
 	"slices"
 	"sort"
 	"strconv"
@@ -10,82 +9,97 @@ import (
 	"github.com/boldandbrad/fffetch/internal/util"
 )
 
-var fantasyFootballFields = []string{
-	"std_pts",
-	"half_ppr_pts",
-	"ppr_pts",
-	"std_ppg",
-	"half_ppr_ppg",
-	"ppr_ppg",
-	"order",
+// missed kicks derived from attempts and makes
+var missedKickFields = map[string][2]string{
+	"fg_missed": {"fga", "fgm"},
+	"xp_missed": {"xpa", "xpm"},
 }
 
-func CalcFFStats(table util.Table) util.Table {
+func CalcFFStats(table util.Table, profiles []Profile) (util.Table, error) {
 	tableMap := table.ToMap()
+	if err := tableMap.Require("player", "g"); err != nil {
+		return util.Table{}, err
+	}
+
+	// add missed kick headers
+	for _, header := range []string{"fg_missed", "xp_missed"} {
+		if !slices.Contains(tableMap.Headers, header) {
+			tableMap.Headers = append(tableMap.Headers, header)
+		}
+	}
+
+	// calculate missed kicks for each player
+	for _, dict := range tableMap.Dicts {
+		for field, source := range missedKickFields {
+			attempts, err := statValue(dict, source[0])
+			if err != nil {
+				return util.Table{}, fmt.Errorf("invalid %s for %s: %w", source[0], dict["player"], err)
+			}
+			makes, err := statValue(dict, source[1])
+			if err != nil {
+				return util.Table{}, fmt.Errorf("invalid %s for %s: %w", source[1], dict["player"], err)
+			}
+			dict[field] = strconv.Itoa(int(attempts - makes))
+		}
+	}
 
+	if err := addScores(&tableMap, profiles, Profile.Score); err != nil {
+		return util.Table{}, err
+	}
+	return tableMap.ToTable(), nil
+}
+
+// addScores adds each profile's points and points per game to every player,
+// along with each player's order by the first profile's points
+func addScores(tableMap *util.TableMap, profiles []Profile, score func(Profile, map[string]string) (float64, error)) error {
 	// add fantasy football stat headers
-	for _, header := range fantasyFootballFields {
+	for _, header := range append(ScoreHeaders(profiles), "order") {
 		if !slices.Contains(tableMap.Headers, header) {
 			tableMap.Headers = append(tableMap.Headers, header)
 		}
 	}
 
 	// calculate fantasy football stats for each player
+	orderPts := make(map[string]float64, len(tableMap.Dicts))
 	for _, dict := range tableMap.Dicts {
-		games, err := strconv.Atoi(dict["g"])
-		rushYds, err := strconv.Atoi(dict["rush_yds"])
-		rushTD, err := strconv.Atoi(dict["rush_td"])
-		rec, err := strconv.Atoi(dict["rec"])
-		recYds, err := strconv.Atoi(dict["rec_yds"])
-		recTD, err := strconv.Atoi(dict["rec_td"])
-		fumbles, err := strconv.Atoi(dict["fumbles"])
-		passYds, err := strconv.Atoi(dict["pass_yds"])
-		passTD, err := strconv.Atoi(dict["pass_td"])
-		passInt, err := strconv.Atoi(dict["pass_int"])
+		games, err := statValue(dict, "g")
 		if err != nil {
-			log.Fatal(err)
+			return fmt.Errorf("invalid g for %s: %w", dict["player"], err)
 		}
 
-		// standard
-		stdPts := (float64(rushYds) * 0.1) + (float64(rushTD) * 6) + (float64(recYds) * 0.1) + (float64(recTD) * 6) + (float64(fumbles) * -1) + (float64(passYds) * 0.04) + (float64(passTD) * 4) + (float64(passInt) * -2)
-		dict["std_pts"] = fmt.Sprintf("%.2f", stdPts)
-		stdPpg := stdPts / float64(games)
-		dict["std_ppg"] = fmt.Sprintf("%.2f", stdPpg)
-
-		// half point per reception
-		halfPprPts := stdPts + (float64(rec) * 0.5)
-		dict["half_ppr_pts"] = fmt.Sprintf("%.2f", halfPprPts)
-		halfPprPpg := halfPprPts / float64(games)
-		dict["half_ppr_ppg"] = fmt.Sprintf("%.2f", halfPprPpg)
-
-		// point per reception
-		pprPts := stdPts + (float64(rec) * 1)
-		dict["ppr_pts"] = fmt.Sprintf("%.2f", pprPts)
-		pprPpg := halfPprPts / float64(games)
-		dict["ppr_ppg"] = fmt.Sprintf("%.2f", pprPpg)
+		for i, profile := range profiles {
+			pts, err := score(profile, dict)
+			if err != nil {
+				return fmt.Errorf("failed to score %s for %s: %w", dict["player"], profile.Name, err)
+			}
+			if i == 0 {
+				orderPts[util.PlayerKey(dict)] = pts
+			}
+			dict[profile.PtsHeader()] = fmt.Sprintf("%.2f", pts)
+			ppg := 0.0
+			if games > 0 {
+				ppg = pts / games
+			}
+			dict[profile.PpgHeader()] = fmt.Sprintf("%.2f", ppg)
+		}
 	}
 
-	// calculate order based on std_pts
+	// calculate order based on the first profile's points
 	dictsCopy := make([]map[string]string, len(tableMap.Dicts))
 	copy(dictsCopy, tableMap.Dicts)
 
 	sort.Slice(dictsCopy, func(i, j int) bool {
-		val1, err := strconv.ParseFloat(dictsCopy[i]["std_pts"], 64)
-		val2, err := strconv.ParseFloat(dictsCopy[j]["std_pts"], 64)
-		if err != nil {
-			log.Fatal(err)
-		}
-		return val1 > val2
+		return orderPts[util.PlayerKey(dictsCopy[i])] > orderPts[util.PlayerKey(dictsCopy[j])]
 	})
 
 	for i, dictcopy := range dictsCopy {
 		for _, dict := range tableMap.Dicts {
-			if dict["player"] == dictcopy["player"] {
+			if util.PlayerKey(dict) == util.PlayerKey(dictcopy) {
 				dict["order"] = strconv.Itoa(i + 1)
 				break
 			}
 		}
 	}
 
-	return tableMap.ToTable()
+	return nil
 }
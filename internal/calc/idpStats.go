@@ -0,0 +1,30 @@
+package calc
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/boldandbrad/fffetch/internal/util"
+)
+
+// offensive and special teams positions left out of individual defense
+var nonDefensivePositions = []string{"QB", "RB", "FB", "WR", "TE", "T", "G", "C", "OL", "K", "P", "LS"}
+
+// CalcIDPStats scores individual defensive players from a team's defense table
+func CalcIDPStats(table util.Table, profiles []Profile) (util.Table, error) {
+	tableMap := table.ToMap()
+	if err := tableMap.Require("player", "pos", "g"); err != nil {
+		return util.Table{}, err
+	}
+	tableMap.Name = "idp"
+
+	// drop offensive players credited with a tackle after a turnover
+	tableMap.Dicts = slices.DeleteFunc(tableMap.Dicts, func(dict map[string]string) bool {
+		return slices.Contains(nonDefensivePositions, strings.ToUpper(dict["pos"]))
+	})
+
+	if err := addScores(&tableMap, profiles, Profile.ScoreIDP); err != nil {
+		return util.Table{}, err
+	}
+	return tableMap.ToTable(), nil
+}
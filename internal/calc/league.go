@@ -0,0 +1,174 @@
+package calc
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/boldandbrad/fffetch/internal/util"
+)
+
+// fields describing a player rather than their production
+var playerInfoFields = []string{"year", "team", "order", "projection", "player", "player_id", "age", "pos"}
+
+// stint is a player's row on one team along with that team's totals
+type stint struct {
+	dict   map[string]string
+	totals map[string]string
+}
+
+// CalcLeagueTable combines every team's final table for a season into one
+// table, merging the rows of players traded mid-season
+func CalcLeagueTable(teamTables []util.Table) (util.Table, error) {
+	var leagueMap util.TableMap
+	leagueMap.Name = "league"
+
+	stints := map[string][]stint{}
+	var keys []string
+	for _, table := range teamTables {
+		tableMap := table.ToMap()
+		for _, header := range tableMap.Headers {
+			if !slices.Contains(leagueMap.Headers, header) {
+				leagueMap.Headers = append(leagueMap.Headers, header)
+			}
+		}
+		for _, dict := range tableMap.Dicts {
+			key := util.PlayerKey(dict)
+			if _, exists := stints[key]; !exists {
+				keys = append(keys, key)
+			}
+			stints[key] = append(stints[key], stint{dict: dict, totals: tableMap.FooterDict})
+		}
+	}
+
+	for _, key := range keys {
+		if len(stints[key]) == 1 {
+			leagueMap.Dicts = append(leagueMap.Dicts, stints[key][0].dict)
+			continue
+		}
+		combined, err := combineStints(leagueMap.Headers, stints[key])
+		if err != nil {
+			return util.Table{}, err
+		}
+		leagueMap.Dicts = append(leagueMap.Dicts, combined)
+	}
+
+	return leagueMap.ToTable(), nil
+}
+
+// OnePerPlayer drops the rows of players who also appear in a later table,
+// so the league tables of each kind, stacked offense, IDP then team defense,
+// give every player a single row. A defender who returns kicks keeps only their
+// IDP row, since their position is a defensive one.
+func OnePerPlayer(tables []util.Table) []util.Table {
+	later := map[string]bool{}
+	kept := make([]util.Table, len(tables))
+	for i := len(tables) - 1; i >= 0; i-- {
+		tableMap := tables[i].ToMap()
+		var dicts []map[string]string
+		for _, dict := range tableMap.Dicts {
+			if !later[util.PlayerKey(dict)] {
+				dicts = append(dicts, dict)
+			}
+		}
+		for _, dict := range tableMap.Dicts {
+			later[util.PlayerKey(dict)] = true
+		}
+		tableMap.Dicts = dicts
+		kept[i] = tableMap.ToTable()
+	}
+	return kept
+}
+
+// combineStints merges a traded player's rows from each team played for
+func combineStints(headers []string, stints []stint) (map[string]string, error) {
+	combined := maps.Clone(stints[0].dict)
+	combined["order"] = ""
+
+	var teams []string
+	for _, s := range stints {
+		teams = append(teams, s.dict["team"])
+	}
+	combined["team"] = strings.Join(teams, "/")
+
+	for _, header := range headers {
+		// ranks are recalculated league-wide
+		if slices.Contains(playerInfoFields, header) || strings.HasSuffix(header, "_ppg") || strings.HasSuffix(header, "_rank") {
+			continue
+		}
+
+		// shares are of the combined totals of every team played for
+		if field, isShare := strings.CutSuffix(header, "%"); isShare {
+			playerSum, teamSum := 0.0, 0.0
+			for _, s := range stints {
+				playerVal, err := statValue(s.dict, field)
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s for %s: %w", field, combined["player"], err)
+				}
+				teamVal, err := statValue(s.totals, field)
+				if err != nil {
+					return nil, fmt.Errorf("invalid team total %s: %w", field, err)
+				}
+				playerSum += playerVal
+				teamSum += teamVal
+			}
+			percentage := 0.0
+			if teamSum != 0 {
+				percentage = playerSum / teamSum
+			}
+			combined[header] = fmt.Sprintf("%.2f%%", percentage*100)
+			continue
+		}
+
+		// longest plays are the best of any stint, everything else adds up
+		total := 0.0
+		for _, s := range stints {
+			val, err := statValue(s.dict, header)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s for %s: %w", header, combined["player"], err)
+			}
+			if strings.HasSuffix(header, "_long") {
+				total = max(total, val)
+			} else {
+				total += val
+			}
+		}
+		if strings.HasSuffix(header, "_pts") {
+			combined[header] = fmt.Sprintf("%.2f", total)
+		} else {
+			combined[header] = formatStat(total)
+		}
+	}
+
+	// points per game follow from the combined points and games
+	games, err := statValue(combined, "g")
+	if err != nil {
+		return nil, fmt.Errorf("invalid g for %s: %w", combined["player"], err)
+	}
+	for _, header := range headers {
+		if prefix, isPpg := strings.CutSuffix(header, "_ppg"); isPpg {
+			pts, err := statValue(combined, prefix+"_pts")
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s_pts for %s: %w", prefix, combined["player"], err)
+			}
+			ppg := 0.0
+			if games > 0 {
+				ppg = pts / games
+			}
+			combined[header] = fmt.Sprintf("%.2f", ppg)
+		}
+	}
+	return combined, nil
+}
+
+// PtsHeadersOf returns the points headers among a table's headers
+func PtsHeadersOf(headers []string) []string {
+	var ptsHeaders []string
+	for _, header := range headers {
+		if strings.HasSuffix(header, "_pts") {
+			ptsHeaders = append(ptsHeaders, header)
+		}
+	}
+	return ptsHeaders
+}
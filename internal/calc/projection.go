@@ -0,0 +1,358 @@
+package calc
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/boldandbrad/fffetch/internal/util"
+)
+
+// AgeCurve describes how a position's production changes with age
+type AgeCurve struct {
+	Peak int `mapstructure:"peak"`
+	// Growth is the fraction production rises per year younger than Peak
+	Growth float64 `mapstructure:"growth"`
+	// Decline is the fraction production falls per year older than Peak
+	Decline float64 `mapstructure:"decline"`
+}
+
+// ProjectionModel holds the parameters used to project a season from the
+// seasons before it
+type ProjectionModel struct {
+	// SeasonWeights weigh past seasons, most recent first
+	SeasonWeights []float64 `mapstructure:"season_weights"`
+	// RegressionGames are the games of positional average production blended
+	// into every player's rates
+	RegressionGames float64 `mapstructure:"regression_games"`
+	// ShareWeight is how much of a player's volume comes from carrying their
+	// share of team stats over to their latest team's totals
+	ShareWeight float64             `mapstructure:"share_weight"`
+	SeasonGames float64             `mapstructure:"season_games"`
+	AgeCurves   map[string]AgeCurve `mapstructure:"age_curves"`
+}
+
+var DEFAULT_PROJECTION_MODEL = ProjectionModel{
+	SeasonWeights:   []float64{5, 4, 3},
+	RegressionGames: 4,
+	ShareWeight:     0.5,
+	SeasonGames:     17,
+	AgeCurves: map[string]AgeCurve{
+		"QB": {Peak: 29, Growth: 0.02, Decline: 0.03},
+		"RB": {Peak: 25, Growth: 0.04, Decline: 0.08},
+		"WR": {Peak: 27, Growth: 0.04, Decline: 0.05},
+		"TE": {Peak: 28, Growth: 0.05, Decline: 0.05},
+		"K":  {Peak: 30, Growth: 0, Decline: 0.01},
+	},
+}
+
+// Season is one year of league data along with each team's totals
+type Season struct {
+	Year  int
+	Table util.Table
+	// TeamTotals are each team's footer totals
+	TeamTotals map[string]map[string]string
+	// Teams are the team each player played the most games for
+	Teams map[string]string
+}
+
+// NewSeason combines a year's final team tables into a season of history
+func NewSeason(year int, teamTables []util.Table) (Season, error) {
+	leagueTable, err := CalcLeagueTable(teamTables)
+	if err != nil {
+		return Season{}, err
+	}
+
+	season := Season{
+		Year:       year,
+		Table:      leagueTable,
+		TeamTotals: map[string]map[string]string{},
+		Teams:      map[string]string{},
+	}
+	mostGames := map[string]float64{}
+	for _, table := range teamTables {
+		tableMap := table.ToMap()
+		if len(tableMap.Dicts) == 0 {
+			continue
+		}
+		team := tableMap.Dicts[0]["team"]
+		season.TeamTotals[team] = tableMap.FooterDict
+		for _, dict := range tableMap.Dicts {
+			games, err := statValue(dict, "g")
+			if err != nil {
+				return Season{}, fmt.Errorf("invalid g for %s: %w", dict["player"], err)
+			}
+			key := util.PlayerKey(dict)
+			if _, exists := season.Teams[key]; !exists || games > mostGames[key] {
+				season.Teams[key] = team
+				mostGames[key] = games
+			}
+		}
+	}
+	return season, nil
+}
+
+// CalcProjections projects each player's stats and fantasy points for a year
+// from the weighted seasons before it, adjusted for age, regressed to their
+// position's average and carrying their share of team stats to their latest
+// team. The projection column holds the first profile's projected points.
+func CalcProjections(seasons []Season, year int, profiles []Profile, model ProjectionModel) (util.Table, error) {
+	var history []Season
+	for _, season := range seasons {
+		if season.Year < year {
+			history = append(history, season)
+		}
+	}
+	slices.SortFunc(history, func(i, j Season) int {
+		return cmp.Compare(j.Year, i.Year)
+	})
+	if len(history) > len(model.SeasonWeights) {
+		history = history[:len(model.SeasonWeights)]
+	}
+	if len(history) == 0 {
+		return util.Table{}, fmt.Errorf("no seasons before %d to project from", year)
+	}
+
+	totalWeight := 0.0
+	for i := range history {
+		totalWeight += model.SeasonWeights[i]
+	}
+
+	posMeans, err := positionalMeans(history[0].Table)
+	if err != nil {
+		return util.Table{}, err
+	}
+
+	// collect each player's seasons, most recent first
+	playerSeasons := map[string][]playerSeason{}
+	var keys []string
+	for i, season := range history {
+		for _, dict := range season.Table.ToMap().Dicts {
+			key := util.PlayerKey(dict)
+			if _, exists := playerSeasons[key]; !exists {
+				keys = append(keys, key)
+			}
+			playerSeasons[key] = append(playerSeasons[key], playerSeason{dict: dict, season: season, weight: model.SeasonWeights[i]})
+		}
+	}
+
+	var projMap util.TableMap
+	projMap.Name = "projections"
+	projMap.Headers = []string{"year", "team", "projection", "player", "player_id", "age", "pos", "g", "gs"}
+	projMap.Headers = append(projMap.Headers, projectedStats...)
+	for _, field := range fieldsToPercent {
+		projMap.Headers = append(projMap.Headers, fmt.Sprintf("%s%%", field))
+	}
+
+	for _, key := range keys {
+		dict, err := projectPlayer(playerSeasons[key], year, history[0].Year, totalWeight, posMeans, model)
+		if err != nil {
+			return util.Table{}, err
+		}
+		if dict != nil {
+			projMap.Dicts = append(projMap.Dicts, dict)
+		}
+	}
+
+	projTable, err := CalcFFStats(projMap.ToTable(), profiles)
+	if err != nil {
+		return util.Table{}, err
+	}
+	projMap = projTable.ToMap()
+	for _, dict := range projMap.Dicts {
+		dict["projection"] = dict[profiles[0].PtsHeader()]
+	}
+	return CalcLeagueRanks(projMap.ToTable(), PtsHeaders(profiles))
+}
+
+// ApplyProjections fills the projection column of a table from a projections
+// table, leaving players without a projection blank
+func ApplyProjections(table util.Table, projections util.Table) util.Table {
+	projected := map[string]string{}
+	for _, dict := range projections.ToMap().Dicts {
+		projected[util.PlayerKey(dict)] = dict["projection"]
+	}
+
+	tableMap := table.ToMap()
+	if !slices.Contains(tableMap.Headers, "projection") {
+		tableMap.Headers = append(tableMap.Headers, "projection")
+	}
+	for _, dict := range tableMap.Dicts {
+		dict["projection"] = projected[util.PlayerKey(dict)]
+	}
+	return tableMap.ToTable()
+}
+
+// counting stats projected from per game rates
+var projectedStats = func() []string {
+	var stats []string
+	for _, header := range util.FINAL_HEADERS {
+		if slices.Contains(playerInfoFields, header) || header == "g" || header == "gs" ||
+			strings.HasSuffix(header, "%") || strings.HasSuffix(header, "_long") {
+			continue
+		}
+		stats = append(stats, header)
+	}
+	return stats
+}()
+
+// projected stats counting mistakes rather than production, left out of age
+// adjustments so an aging curve doesn't shrink a declining player's turnovers
+var unagedStats = []string{"fumbles", "pass_int", "times sacked", "pass_sacked_yds", "fg_missed", "xp_missed"}
+
+// playerSeason is a player's row in a season of history and that season's weight
+type playerSeason struct {
+	dict   map[string]string
+	season Season
+	weight float64
+}
+
+// positionalMeans returns each position's per game rate of every projected stat
+func positionalMeans(table util.Table) (map[string]map[string]float64, error) {
+	totals := map[string]map[string]float64{}
+	games := map[string]float64{}
+	for _, dict := range table.ToMap().Dicts {
+		g, err := statValue(dict, "g")
+		if err != nil {
+			return nil, fmt.Errorf("invalid g for %s: %w", dict["player"], err)
+		}
+		pos := dict["pos"]
+		if _, exists := totals[pos]; !exists {
+			totals[pos] = map[string]float64{}
+		}
+		games[pos] += g
+		for _, stat := range projectedStats {
+			val, err := statValue(dict, stat)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s for %s: %w", stat, dict["player"], err)
+			}
+			totals[pos][stat] += val
+		}
+	}
+
+	means := map[string]map[string]float64{}
+	for pos, posTotals := range totals {
+		means[pos] = map[string]float64{}
+		for stat, total := range posTotals {
+			if games[pos] > 0 {
+				means[pos][stat] = total / games[pos]
+			}
+		}
+	}
+	return means, nil
+}
+
+// projectPlayer projects one player's season from their weighted history,
+// returning nil for players who never played a game or are missing from the
+// latest season, such as retired players
+func projectPlayer(history []playerSeason, year int, latestYear int, totalWeight float64, posMeans map[string]map[string]float64, model ProjectionModel) (map[string]string, error) {
+	latest := history[0]
+	if latest.season.Year != latestYear {
+		return nil, nil
+	}
+	key := util.PlayerKey(latest.dict)
+
+	weights, weightedGames, weightedStarts := 0.0, 0.0, 0.0
+	weightedStats := map[string]float64{}
+	weightedShares := map[string]float64{}
+	for _, s := range history {
+		games, err := statValue(s.dict, "g")
+		if err != nil {
+			return nil, fmt.Errorf("invalid g for %s: %w", s.dict["player"], err)
+		}
+		starts, err := statValue(s.dict, "gs")
+		if err != nil {
+			return nil, fmt.Errorf("invalid gs for %s: %w", s.dict["player"], err)
+		}
+		weights += s.weight
+		weightedGames += s.weight * games
+		weightedStarts += s.weight * starts
+		for _, stat := range projectedStats {
+			val, err := statValue(s.dict, stat)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s for %s: %w", stat, s.dict["player"], err)
+			}
+			weightedStats[stat] += s.weight * val
+		}
+		// shares per game played, so missed games don't shrink them
+		if games == 0 {
+			continue
+		}
+		for _, field := range fieldsToPercent {
+			share, err := statValue(s.dict, field+"%")
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s%% for %s: %w", field, s.dict["player"], err)
+			}
+			weightedShares[field] += s.weight * share / 100 / games
+		}
+	}
+	if weightedGames == 0 {
+		return nil, nil
+	}
+
+	// seasons missing from the player's history count as no evidence, so
+	// players with short histories regress further toward the mean
+	evidenceGames := weightedGames / totalWeight
+	regression := model.RegressionGames
+	posMean := posMeans[latest.dict["pos"]]
+
+	age, ageErr := strconv.Atoi(latest.dict["age"])
+	projectedAge := ""
+	ageFactor := 1.0
+	if ageErr == nil {
+		age += year - latest.season.Year
+		projectedAge = strconv.Itoa(age)
+		ageFactor = model.ageFactor(latest.dict["pos"], age)
+	}
+
+	// team shares carry over to the team played for most in the latest season
+	team := latest.season.Teams[key]
+	teamTotals := latest.season.TeamTotals[team]
+
+	projectedGames := min(weightedGames/weights, model.SeasonGames)
+	dict := map[string]string{
+		"year":      strconv.Itoa(year),
+		"team":      team,
+		"player":    latest.dict["player"],
+		"player_id": latest.dict["player_id"],
+		"age":       projectedAge,
+		"pos":       latest.dict["pos"],
+		"g":         fmt.Sprintf("%.1f", projectedGames),
+		"gs":        fmt.Sprintf("%.1f", min(weightedStarts/weights, projectedGames)),
+	}
+
+	for _, stat := range projectedStats {
+		rate := weightedStats[stat] / weightedGames
+		if share, isShared := weightedShares[stat]; isShared && teamTotals != nil {
+			teamTotal, err := statValue(teamTotals, stat)
+			if err != nil {
+				return nil, fmt.Errorf("invalid team total %s: %w", stat, err)
+			}
+			rate = (1-model.ShareWeight)*rate + model.ShareWeight*(share/weights)*teamTotal
+		}
+		rate = (evidenceGames*rate + regression*posMean[stat]) / (evidenceGames + regression)
+		if !slices.Contains(unagedStats, stat) {
+			rate *= ageFactor
+		}
+		dict[stat] = fmt.Sprintf("%.1f", rate*projectedGames)
+	}
+	for _, field := range fieldsToPercent {
+		share := weightedShares[field] / weights * projectedGames
+		dict[fmt.Sprintf("%s%%", field)] = fmt.Sprintf("%.2f%%", share*100)
+	}
+	return dict, nil
+}
+
+// ageFactor scales production for a player's age at their position
+func (m ProjectionModel) ageFactor(pos string, age int) float64 {
+	curve, exists := m.AgeCurves[strings.ToUpper(pos)]
+	if !exists {
+		return 1
+	}
+	if age < curve.Peak {
+		return 1 + curve.Growth*float64(curve.Peak-age)
+	}
+	return max(1-curve.Decline*float64(age-curve.Peak), 0)
+}
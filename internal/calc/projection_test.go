@@ -0,0 +1,83 @@
+package calc
+
+import (
+	"testing"
+)
+
+func TestProjectPlayerShareMatchesRate(t *testing.T) {
+	// one season of a receiver whose 25% target share of the team's 200
+	// targets is exactly their 5 targets per game
+	dict := map[string]string{
+		"player":    "Receiver",
+		"player_id": "ReceXx00",
+		"pos":       "WR",
+		"g":         "10",
+		"gs":        "10",
+		"targets":   "50",
+		"targets%":  "25.00%",
+	}
+	season := Season{
+		Year:       2024,
+		TeamTotals: map[string]map[string]string{"KC": {"targets": "200"}},
+		Teams:      map[string]string{"ReceXx00": "KC"},
+	}
+	model := ProjectionModel{
+		SeasonWeights: []float64{5, 4, 3},
+		ShareWeight:   0.5,
+		SeasonGames:   17,
+	}
+
+	projected, err := projectPlayer([]playerSeason{{dict: dict, season: season, weight: 5}}, 2025, 2024, 5, nil, model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projected["targets"] != "50.0" {
+		t.Errorf("targets = %s, want 50.0", projected["targets"])
+	}
+	if projected["targets%"] != "25.00%" {
+		t.Errorf("targets%% = %s, want 25.00%%", projected["targets%"])
+	}
+}
+
+func TestProjectPlayerAgesOnlyProduction(t *testing.T) {
+	// a player two years past their peak loses production but not turnovers
+	dict := map[string]string{
+		"player":    "Passer",
+		"player_id": "PassXx00",
+		"pos":       "QB",
+		"age":       "30",
+		"g":         "10",
+		"pass_yds":  "2000",
+		"pass_int":  "10",
+	}
+	model := ProjectionModel{
+		SeasonWeights: []float64{1},
+		SeasonGames:   17,
+		AgeCurves:     map[string]AgeCurve{"QB": {Peak: 29, Decline: 0.1}},
+	}
+
+	projected, err := projectPlayer([]playerSeason{{dict: dict, season: Season{Year: 2024}, weight: 1}}, 2025, 2024, 1, nil, model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projected["pass_yds"] != "1600.0" {
+		t.Errorf("pass_yds = %s, want 1600.0", projected["pass_yds"])
+	}
+	if projected["pass_int"] != "10.0" {
+		t.Errorf("pass_int = %s, want 10.0", projected["pass_int"])
+	}
+}
+
+func TestProjectPlayerSkipsMissingFromLatestSeason(t *testing.T) {
+	// a player who last played two seasons ago, e.g. since retired
+	dict := map[string]string{"player": "Retired", "player_id": "RetiXx00", "pos": "RB", "g": "17", "rush_att": "200"}
+	model := ProjectionModel{SeasonWeights: []float64{5, 4, 3}, SeasonGames: 17}
+
+	projected, err := projectPlayer([]playerSeason{{dict: dict, season: Season{Year: 2023}, weight: 4}}, 2025, 2024, 12, nil, model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projected != nil {
+		t.Errorf("projected %v, want no projection", projected)
+	}
+}
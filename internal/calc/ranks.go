@@ -0,0 +1,92 @@
+package calc
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/boldandbrad/fffetch/internal/util"
+)
+
+// CalcPosRanks ranks players within their position by each points header
+func CalcPosRanks(table util.Table, ptsHeaders []string) (util.Table, error) {
+	tableMap := table.ToMap()
+	if err := rankPlayers(&tableMap, ptsHeaders, false); err != nil {
+		return util.Table{}, err
+	}
+	return tableMap.ToTable(), nil
+}
+
+// CalcLeagueRanks ranks players within their position and overall by each
+// points header, ordering players by the first
+func CalcLeagueRanks(table util.Table, ptsHeaders []string) (util.Table, error) {
+	tableMap := table.ToMap()
+	if err := rankPlayers(&tableMap, ptsHeaders, true); err != nil {
+		return util.Table{}, err
+	}
+
+	firstRankHeader := rankHeader(ptsHeaders[0], "rank")
+	slices.SortStableFunc(tableMap.Dicts, func(i, j map[string]string) int {
+		rank1, _ := strconv.Atoi(i[firstRankHeader])
+		rank2, _ := strconv.Atoi(j[firstRankHeader])
+		return cmp.Compare(rank1, rank2)
+	})
+	return tableMap.ToTable(), nil
+}
+
+func rankPlayers(tableMap *util.TableMap, ptsHeaders []string, overall bool) error {
+	if len(ptsHeaders) == 0 {
+		return &util.MissingColumnError{Table: tableMap.Name, Column: "_pts"}
+	}
+	if err := tableMap.Require(append([]string{"pos"}, ptsHeaders...)...); err != nil {
+		return err
+	}
+
+	for _, ptsHeader := range ptsHeaders {
+		posRankHeader := rankHeader(ptsHeader, "pos_rank")
+		overallRankHeader := rankHeader(ptsHeader, "rank")
+		newHeaders := []string{posRankHeader}
+		if overall {
+			newHeaders = append(newHeaders, overallRankHeader)
+		}
+		for _, header := range newHeaders {
+			if !slices.Contains(tableMap.Headers, header) {
+				tableMap.Headers = append(tableMap.Headers, header)
+			}
+		}
+
+		// parse points up front so sorting can't fail midway
+		pts := make(map[string]float64, len(tableMap.Dicts))
+		for _, dict := range tableMap.Dicts {
+			val, err := statValue(dict, ptsHeader)
+			if err != nil {
+				return fmt.Errorf("invalid %s for %s: %w", ptsHeader, dict["player"], err)
+			}
+			pts[util.PlayerKey(dict)] = val
+		}
+
+		ranked := slices.Clone(tableMap.Dicts)
+		slices.SortStableFunc(ranked, func(i, j map[string]string) int {
+			return cmp.Compare(pts[util.PlayerKey(j)], pts[util.PlayerKey(i)])
+		})
+
+		// positions can be lower case, e.g. for non-starters
+		posCounts := map[string]int{}
+		for i, dict := range ranked {
+			pos := strings.ToUpper(dict["pos"])
+			posCounts[pos] += 1
+			dict[posRankHeader] = strconv.Itoa(posCounts[pos])
+			if overall {
+				dict[overallRankHeader] = strconv.Itoa(i + 1)
+			}
+		}
+	}
+	return nil
+}
+
+// rankHeader names the rank column for a points column, e.g. std_pos_rank
+func rankHeader(ptsHeader string, rank string) string {
+	return fmt.Sprintf("%s_%s", strings.TrimSuffix(ptsHeader, "_pts"), rank)
+}
@@ -0,0 +1,271 @@
+package calc
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Bonus awards Points when a stat reaches Threshold
+type Bonus struct {
+	Stat      string  `mapstructure:"stat"`
+	Threshold float64 `mapstructure:"threshold"`
+	Points    float64 `mapstructure:"points"`
+}
+
+// Profile is a named set of fantasy football scoring rules
+type Profile struct {
+	Name string `mapstructure:"-"`
+	// Base names a profile whose rules this profile extends
+	Base string `mapstructure:"base"`
+	// Weights are points awarded per unit of each stat
+	Weights map[string]float64 `mapstructure:"weights"`
+	// Positions are per-position multipliers applied to stat weights
+	Positions map[string]map[string]float64 `mapstructure:"positions"`
+	Bonuses   []Bonus                       `mapstructure:"bonuses"`
+	// DST are points awarded per unit of each team defense/special teams stat
+	DST map[string]float64 `mapstructure:"dst"`
+	// IDP are points awarded per unit of each individual defensive player stat
+	IDP map[string]float64 `mapstructure:"idp"`
+}
+
+// Built-in scoring profiles
+var DEFAULT_PROFILES = map[string]Profile{
+	"std": {
+		Weights: map[string]float64{
+			"rush_yds": 0.1,
+			"rush_td":  6,
+			"rec_yds":  0.1,
+			"rec_td":   6,
+			"fumbles":  -1,
+			"pass_yds": 0.04,
+			"pass_td":  4,
+			"pass_int": -2,
+			// returns
+			"kick_ret_yds": 0,
+			"kick_ret_td":  6,
+			"punt_ret_yds": 0,
+			"punt_ret_td":  6,
+			// kicking
+			"fgm_0_19":    3,
+			"fgm_20_29":   3,
+			"fgm_30_39":   3,
+			"fgm_40_49":   4,
+			"fgm_50_plus": 5,
+			"fg_missed":   -1,
+			"xpm":         1,
+			"xp_missed":   -1,
+		},
+		DST: map[string]float64{
+			"sacks":       1,
+			"def_int":     2,
+			"fumbles_rec": 2,
+			"def_td":      6,
+			"ret_td":      6,
+			"safety":      2,
+			// games by points allowed
+			"pa_0":       10,
+			"pa_1_6":     7,
+			"pa_7_13":    4,
+			"pa_14_20":   1,
+			"pa_21_27":   0,
+			"pa_28_34":   -1,
+			"pa_35_plus": -4,
+		},
+		IDP: map[string]float64{
+			"tackles_solo":    1,
+			"tackles_assists": 0.5,
+			"tackles_loss":    1,
+			"sacks":           2,
+			"qb_hits":         0.5,
+			"pass_defended":   1,
+			"def_int":         3,
+			"fumbles_forced":  2,
+			"fumbles_rec":     2,
+			"def_int_td":      6,
+			"fumbles_rec_td":  6,
+			"safety_md":       2,
+		},
+	},
+	"half_ppr": {
+		Base:    "std",
+		Weights: map[string]float64{"rec": 0.5},
+	},
+	"ppr": {
+		Base:    "std",
+		Weights: map[string]float64{"rec": 1},
+	},
+}
+
+var DEFAULT_PROFILE_NAMES = []string{"std", "half_ppr", "ppr"}
+
+// ResolveProfiles looks up each named profile among the custom and built-in
+// profiles, custom taking precedence, and flattens any base profiles. Names
+// are case-insensitive, since config keys are read in lower case.
+func ResolveProfiles(names []string, custom map[string]Profile) ([]Profile, error) {
+	lookup := maps.Clone(DEFAULT_PROFILES)
+	for name, profile := range custom {
+		lookup[strings.ToLower(name)] = profile
+	}
+
+	var profiles []Profile
+	for _, name := range names {
+		profile, err := resolveProfile(strings.ToLower(name), lookup, []string{})
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+func resolveProfile(name string, lookup map[string]Profile, seen []string) (Profile, error) {
+	if slices.Contains(seen, name) {
+		return Profile{}, fmt.Errorf("scoring profile %s extends itself", name)
+	}
+	profile, exists := lookup[name]
+	if !exists {
+		return Profile{}, fmt.Errorf("unknown scoring profile: %s", name)
+	}
+
+	resolved := Profile{
+		Name:      name,
+		Weights:   map[string]float64{},
+		Positions: map[string]map[string]float64{},
+		DST:       map[string]float64{},
+		IDP:       map[string]float64{},
+	}
+	if profile.Base != "" {
+		base, err := resolveProfile(strings.ToLower(profile.Base), lookup, append(seen, name))
+		if err != nil {
+			return Profile{}, err
+		}
+		maps.Copy(resolved.Weights, base.Weights)
+		for pos, multipliers := range base.Positions {
+			resolved.Positions[pos] = maps.Clone(multipliers)
+		}
+		resolved.Bonuses = slices.Clone(base.Bonuses)
+		maps.Copy(resolved.DST, base.DST)
+		maps.Copy(resolved.IDP, base.IDP)
+	}
+
+	maps.Copy(resolved.Weights, profile.Weights)
+	maps.Copy(resolved.DST, profile.DST)
+	maps.Copy(resolved.IDP, profile.IDP)
+	// config keys are case-insensitive, so positions are normalized to upper case
+	for pos, multipliers := range profile.Positions {
+		pos = strings.ToUpper(pos)
+		if _, exists := resolved.Positions[pos]; !exists {
+			resolved.Positions[pos] = map[string]float64{}
+		}
+		maps.Copy(resolved.Positions[pos], multipliers)
+	}
+	resolved.Bonuses = append(resolved.Bonuses, profile.Bonuses...)
+	return resolved, nil
+}
+
+// NamedProfile returns a profile with just a name, for finding the named
+// profile's columns in data already scored. Names are case-insensitive, as in
+// ResolveProfiles.
+func NamedProfile(name string) Profile {
+	return Profile{Name: strings.ToLower(name)}
+}
+
+// PtsHeader returns the name of the profile's total points column
+func (p Profile) PtsHeader() string {
+	return fmt.Sprintf("%s_pts", p.Name)
+}
+
+// PpgHeader returns the name of the profile's points per game column
+func (p Profile) PpgHeader() string {
+	return fmt.Sprintf("%s_ppg", p.Name)
+}
+
+// Score calculates the fantasy points a player's stats are worth
+func (p Profile) Score(dict map[string]string) (float64, error) {
+	pts, err := scoreWeights(dict, p.Weights, p.Positions[strings.ToUpper(dict["pos"])])
+	if err != nil {
+		return 0, err
+	}
+
+	for _, bonus := range p.Bonuses {
+		val, err := statValue(dict, bonus.Stat)
+		if err != nil {
+			return 0, err
+		}
+		if val >= bonus.Threshold {
+			pts += bonus.Points
+		}
+	}
+	return pts, nil
+}
+
+// ScoreDST calculates the fantasy points a team defense's stats are worth
+func (p Profile) ScoreDST(dict map[string]string) (float64, error) {
+	return scoreWeights(dict, p.DST, nil)
+}
+
+// ScoreIDP calculates the fantasy points a defensive player's stats are worth
+func (p Profile) ScoreIDP(dict map[string]string) (float64, error) {
+	return scoreWeights(dict, p.IDP, p.Positions[strings.ToUpper(dict["pos"])])
+}
+
+func scoreWeights(dict map[string]string, weights map[string]float64, multipliers map[string]float64) (float64, error) {
+	pts := 0.0
+	// iterate in a fixed order so float sums are reproducible between runs
+	for _, stat := range slices.Sorted(maps.Keys(weights)) {
+		val, err := statValue(dict, stat)
+		if err != nil {
+			return 0, err
+		}
+		weight := weights[stat]
+		if multiplier, exists := multipliers[stat]; exists {
+			weight *= multiplier
+		}
+		pts += val * weight
+	}
+	return pts, nil
+}
+
+// PtsHeaders returns the points headers for profiles
+func PtsHeaders(profiles []Profile) []string {
+	var headers []string
+	for _, profile := range profiles {
+		headers = append(headers, profile.PtsHeader())
+	}
+	return headers
+}
+
+// PosRankHeader returns the name of the profile's positional rank column
+func (p Profile) PosRankHeader() string {
+	return fmt.Sprintf("%s_pos_rank", p.Name)
+}
+
+// OutputHeaders returns the points, points per game and positional rank
+// headers for profiles
+func OutputHeaders(profiles []Profile) []string {
+	headers := ScoreHeaders(profiles)
+	for _, profile := range profiles {
+		headers = append(headers, profile.PosRankHeader())
+	}
+	return headers
+}
+
+// ScoreHeaders returns the points and points per game headers for profiles
+func ScoreHeaders(profiles []Profile) []string {
+	headers := PtsHeaders(profiles)
+	for _, profile := range profiles {
+		headers = append(headers, profile.PpgHeader())
+	}
+	return headers
+}
+
+func statValue(dict map[string]string, stat string) (float64, error) {
+	value := strings.TrimSuffix(dict[stat], "%")
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
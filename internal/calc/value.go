@@ -0,0 +1,264 @@
+package calc
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/boldandbrad/fffetch/internal/util"
+)
+
+// League describes the size and starting lineup of a fantasy league
+type League struct {
+	Teams int `mapstructure:"teams"`
+	// Slots are the starters each team plays at each position or flex slot
+	Slots map[string]int `mapstructure:"slots"`
+}
+
+var DEFAULT_LEAGUE = League{
+	Teams: 12,
+	Slots: map[string]int{"QB": 1, "RB": 2, "WR": 2, "TE": 1, "FLEX": 1, "K": 1, "DST": 1},
+}
+
+// Clone returns a copy of the league that can be changed independently
+func (l League) Clone() League {
+	l.Slots = maps.Clone(l.Slots)
+	return l
+}
+
+// positions each flex slot can be filled from
+var FLEX_POSITIONS = map[string][]string{
+	"FLEX":      {"RB", "WR", "TE"},
+	"SUPERFLEX": {"QB", "RB", "WR", "TE"},
+	"IDP_FLEX":  {"DE", "DT", "LB", "CB", "S"},
+}
+
+// DEFAULT_TIER_GAP is the points drop, in standard deviations of a position's
+// startable players, that begins a new tier
+const DEFAULT_TIER_GAP = 0.5
+
+// RankingHeaders returns the headers of a rankings table for a points header
+func RankingHeaders(ptsHeader string) []string {
+	prefix := strings.TrimSuffix(ptsHeader, "_pts")
+	return []string{
+		"year",
+		prefix + "_draft_rank",
+		"player",
+		"player_id",
+		"team",
+		"age",
+		"pos",
+		"g",
+		ptsHeader,
+		prefix + "_ppg",
+		prefix + "_pos_rank",
+		prefix + "_tier",
+		prefix + "_vor",
+	}
+}
+
+// CalcValues ranks the players at each of a league's positions by their points
+// over the best player that would go unstarted at their position, grouping
+// each position into tiers. Players at positions the league doesn't start are
+// dropped.
+func CalcValues(table util.Table, ptsHeader string, league League, tierGap float64) (util.Table, error) {
+	tableMap := table.ToMap()
+	if err := tableMap.Require("pos", ptsHeader); err != nil {
+		return util.Table{}, err
+	}
+	prefix := strings.TrimSuffix(ptsHeader, "_pts")
+	vorHeader, tierHeader, draftRankHeader := prefix+"_vor", prefix+"_tier", prefix+"_draft_rank"
+	for _, header := range []string{vorHeader, tierHeader, draftRankHeader} {
+		if !slices.Contains(tableMap.Headers, header) {
+			tableMap.Headers = append(tableMap.Headers, header)
+		}
+	}
+
+	// group players by position, best first
+	pts := make(map[string]float64, len(tableMap.Dicts))
+	byPos := map[string][]map[string]string{}
+	for _, dict := range tableMap.Dicts {
+		val, err := statValue(dict, ptsHeader)
+		if err != nil {
+			return util.Table{}, fmt.Errorf("invalid %s for %s: %w", ptsHeader, dict["player"], err)
+		}
+		pts[util.PlayerKey(dict)] = val
+		pos := strings.ToUpper(dict["pos"])
+		byPos[pos] = append(byPos[pos], dict)
+	}
+	for _, dicts := range byPos {
+		slices.SortStableFunc(dicts, func(i, j map[string]string) int {
+			return cmp.Compare(pts[util.PlayerKey(j)], pts[util.PlayerKey(i)])
+		})
+	}
+
+	starters := league.starters(byPos, pts)
+
+	var ranked []map[string]string
+	vors := map[string]float64{}
+	for pos, count := range starters {
+		dicts := byPos[pos]
+		replacement := 0.0
+		if count < len(dicts) {
+			replacement = pts[util.PlayerKey(dicts[count])]
+		}
+		for _, dict := range dicts {
+			vor := pts[util.PlayerKey(dict)] - replacement
+			vors[util.PlayerKey(dict)] = vor
+			dict[vorHeader] = fmt.Sprintf("%.2f", vor)
+		}
+		// positions only started at flex may have no starters, so the spread
+		// is taken over at least a player per team
+		assignTiers(dicts, pts, min(max(2*count, league.Teams), len(dicts)), tierGap, tierHeader)
+		ranked = append(ranked, dicts...)
+	}
+
+	slices.SortStableFunc(ranked, func(i, j map[string]string) int {
+		return cmp.Or(
+			cmp.Compare(vors[util.PlayerKey(j)], vors[util.PlayerKey(i)]),
+			cmp.Compare(pts[util.PlayerKey(j)], pts[util.PlayerKey(i)]),
+			cmp.Compare(util.PlayerKey(i), util.PlayerKey(j)),
+		)
+	})
+	for i, dict := range ranked {
+		dict[draftRankHeader] = strconv.Itoa(i + 1)
+	}
+
+	tableMap.Dicts = ranked
+	tableMap.FooterDict = map[string]string{}
+	return tableMap.ToTable(), nil
+}
+
+// starters returns how many players at each position the league starts,
+// filling flex slots with the best players left after the fixed slots
+func (l League) starters(byPos map[string][]map[string]string, pts map[string]float64) map[string]int {
+	slots := NormalizeSlots(l.Slots)
+
+	starters := map[string]int{}
+	var flexSlots []string
+	for slot, count := range slots {
+		if _, isFlex := FLEX_POSITIONS[slot]; isFlex {
+			flexSlots = append(flexSlots, slot)
+			continue
+		}
+		starters[slot] += l.Teams * count
+	}
+
+	SortFlexSlots(flexSlots)
+	for _, slot := range flexSlots {
+		for range l.Teams * slots[slot] {
+			best := ""
+			bestPts := math.Inf(-1)
+			for _, pos := range FLEX_POSITIONS[slot] {
+				if starters[pos] < len(byPos[pos]) {
+					if val := pts[util.PlayerKey(byPos[pos][starters[pos]])]; val > bestPts {
+						best, bestPts = pos, val
+					}
+				}
+			}
+			if best == "" {
+				break
+			}
+			starters[best] += 1
+		}
+	}
+
+	// flex positions without a fixed slot still need a replacement level
+	for _, slot := range flexSlots {
+		for _, pos := range FLEX_POSITIONS[slot] {
+			if _, exists := starters[pos]; !exists && len(byPos[pos]) > 0 {
+				starters[pos] = 0
+			}
+		}
+	}
+	return starters
+}
+
+// NormalizeSlots returns starting slots keyed in upper case, since config keys
+// are case-insensitive
+func NormalizeSlots(slots map[string]int) map[string]int {
+	normalized := map[string]int{}
+	for slot, count := range slots {
+		normalized[strings.ToUpper(slot)] += count
+	}
+	return normalized
+}
+
+// FillLineup starts players, given by position and sorted best first, in the
+// starting slots: the fixed slots first, then the flex slots that could
+// otherwise take their players. It reports whether each player starts.
+func FillLineup(positions []string, slots map[string]int) []bool {
+	started := make([]bool, len(positions))
+	fill := func(slotPositions []string, count int) {
+		for i, pos := range positions {
+			if count == 0 {
+				return
+			}
+			if !started[i] && slices.Contains(slotPositions, pos) {
+				started[i] = true
+				count -= 1
+			}
+		}
+	}
+
+	var flexSlots []string
+	for _, slot := range slices.Sorted(maps.Keys(slots)) {
+		if _, isFlex := FLEX_POSITIONS[slot]; isFlex {
+			flexSlots = append(flexSlots, slot)
+			continue
+		}
+		fill([]string{slot}, slots[slot])
+	}
+	SortFlexSlots(flexSlots)
+	for _, slot := range flexSlots {
+		fill(FLEX_POSITIONS[slot], slots[slot])
+	}
+	return started
+}
+
+// SortFlexSlots orders flex slots to be filled most restrictive first, so
+// wider slots get the players the narrower ones pass over
+func SortFlexSlots(slots []string) {
+	slices.SortFunc(slots, func(i, j string) int {
+		return cmp.Or(cmp.Compare(len(FLEX_POSITIONS[i]), len(FLEX_POSITIONS[j])), cmp.Compare(i, j))
+	})
+}
+
+// assignTiers splits players sorted by points into tiers, starting a new tier
+// wherever the drop to the next player is large compared to the spread of the
+// top players
+func assignTiers(dicts []map[string]string, pts map[string]float64, top int, tierGap float64, tierHeader string) {
+	var topPts []float64
+	for _, dict := range dicts[:top] {
+		topPts = append(topPts, pts[util.PlayerKey(dict)])
+	}
+	threshold := tierGap * stdDev(topPts)
+
+	tier := 1
+	for i, dict := range dicts {
+		if i > 0 && pts[util.PlayerKey(dicts[i-1])]-pts[util.PlayerKey(dict)] > threshold {
+			tier += 1
+		}
+		dict[tierHeader] = strconv.Itoa(tier)
+	}
+}
+
+func stdDev(vals []float64) float64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	mean := 0.0
+	for _, val := range vals {
+		mean += val
+	}
+	mean /= float64(len(vals))
+	variance := 0.0
+	for _, val := range vals {
+		variance += (val - mean) * (val - mean)
+	}
+	return math.Sqrt(variance / float64(len(vals)))
+}
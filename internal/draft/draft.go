@@ -0,0 +1,312 @@
+package draft
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/boldandbrad/fffetch/internal/calc"
+	"github.com/boldandbrad/fffetch/internal/util"
+)
+
+// DEFAULT_BENCH is the bench spots each team drafts beyond its starters
+const DEFAULT_BENCH = 6
+
+// DEFAULT_BUDGET is each team's auction budget
+const DEFAULT_BUDGET = 200
+
+// Player is a player available in the draft
+type Player struct {
+	ID   string
+	Name string
+	Pos  string
+	Team string
+	Pts  float64
+	VOR  float64
+	// Rank is the player's overall draft rank, standing in for their ADP
+	Rank int
+	// Value is the player's auction value
+	Value int
+}
+
+// Team is a fantasy team drafting players with a strategy
+type Team struct {
+	Name     string
+	Strategy Strategy
+	Roster   []*Player
+	Budget   int
+}
+
+// Pick is one player drafted
+type Pick struct {
+	Number int
+	Round  int
+	Team   *Team
+	Player *Player
+	// Price is what the player went for in an auction
+	Price int
+}
+
+// Draft is a mock draft between teams
+type Draft struct {
+	Slots     map[string]int
+	Bench     int
+	Teams     []*Team
+	Available []*Player
+	Picks     []Pick
+	Rng       *rand.Rand
+}
+
+// NewPlayers reads the draft pool from a rankings table
+func NewPlayers(table util.Table, ptsHeader string) ([]*Player, error) {
+	prefix := strings.TrimSuffix(ptsHeader, "_pts")
+	tableMap := table.ToMap()
+	if err := tableMap.Require("player", "pos", ptsHeader, prefix+"_vor", prefix+"_draft_rank"); err != nil {
+		return nil, err
+	}
+
+	var players []*Player
+	for _, dict := range tableMap.Dicts {
+		pts, err := strconv.ParseFloat(dict[ptsHeader], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s for %s: %w", ptsHeader, dict["player"], err)
+		}
+		vor, err := strconv.ParseFloat(dict[prefix+"_vor"], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s_vor for %s: %w", prefix, dict["player"], err)
+		}
+		rank, err := strconv.Atoi(dict[prefix+"_draft_rank"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s_draft_rank for %s: %w", prefix, dict["player"], err)
+		}
+		players = append(players, &Player{
+			ID:   util.PlayerKey(dict),
+			Name: dict["player"],
+			Pos:  strings.ToUpper(dict["pos"]),
+			Team: dict["team"],
+			Pts:  pts,
+			VOR:  vor,
+			Rank: rank,
+		})
+	}
+	slices.SortStableFunc(players, func(i, j *Player) int {
+		return cmp.Compare(i.Rank, j.Rank)
+	})
+	return players, nil
+}
+
+// New sets up a draft of the given players between teams with a league's
+// starting lineup and bench, valuing players for an auction of each team's
+// budget
+func New(league calc.League, bench int, budget int, players []*Player, teams []*Team, rng *rand.Rand) *Draft {
+	d := &Draft{
+		Slots:     calc.NormalizeSlots(league.Slots),
+		Bench:     bench,
+		Teams:     teams,
+		Available: slices.Clone(players),
+		Rng:       rng,
+	}
+	for _, team := range teams {
+		team.Budget = budget
+	}
+	d.setValues(budget)
+	return d
+}
+
+// RosterSize is the number of players each team drafts
+func (d *Draft) RosterSize() int {
+	size := d.Bench
+	for _, count := range d.Slots {
+		size += count
+	}
+	return size
+}
+
+// setValues prices players by their share of the value over replacement of
+// every player that will be drafted, after the $1 each roster spot costs
+func (d *Draft) setValues(budget int) {
+	drafted := min(len(d.Teams)*d.RosterSize(), len(d.Available))
+	byVOR := slices.Clone(d.Available)
+	slices.SortStableFunc(byVOR, func(i, j *Player) int {
+		return cmp.Compare(j.VOR, i.VOR)
+	})
+
+	totalVOR := 0.0
+	for _, player := range byVOR[:drafted] {
+		totalVOR += max(player.VOR, 0)
+	}
+	surplus := float64(len(d.Teams)*budget - drafted)
+	for _, player := range d.Available {
+		player.Value = 1
+		if totalVOR > 0 && player.VOR > 0 {
+			player.Value += int(math.Round(player.VOR / totalVOR * surplus))
+		}
+	}
+}
+
+// CanDraft reports whether a team has room on its roster for a player
+func (d *Draft) CanDraft(team *Team, player *Player) bool {
+	_, bench := d.Lineup(append(slices.Clone(team.Roster), player))
+	return len(bench) <= d.Bench
+}
+
+// FillsStarter reports whether a player would fill one of a team's open
+// starting slots
+func (d *Draft) FillsStarter(team *Team, player *Player) bool {
+	starters, _ := d.Lineup(team.Roster)
+	withPlayer, _ := d.Lineup(append(slices.Clone(team.Roster), player))
+	return len(withPlayer) > len(starters)
+}
+
+// Draftable returns the available players a team has room for, best ranked
+// first
+func (d *Draft) Draftable(team *Team) []*Player {
+	var players []*Player
+	for _, player := range d.Available {
+		if d.CanDraft(team, player) {
+			players = append(players, player)
+		}
+	}
+	return players
+}
+
+// Lineup splits players into the best starting lineup by points and the bench
+func (d *Draft) Lineup(players []*Player) ([]*Player, []*Player) {
+	sorted := slices.Clone(players)
+	slices.SortStableFunc(sorted, func(i, j *Player) int {
+		return cmp.Compare(j.Pts, i.Pts)
+	})
+	var positions []string
+	for _, player := range sorted {
+		positions = append(positions, player.Pos)
+	}
+
+	var starters, bench []*Player
+	for i, started := range calc.FillLineup(positions, d.Slots) {
+		if started {
+			starters = append(starters, sorted[i])
+		} else {
+			bench = append(bench, sorted[i])
+		}
+	}
+	return starters, bench
+}
+
+// ProjectedPoints are the season points of a team's best starting lineup
+func (d *Draft) ProjectedPoints(team *Team) float64 {
+	starters, _ := d.Lineup(team.Roster)
+	total := 0.0
+	for _, player := range starters {
+		total += player.Pts
+	}
+	return total
+}
+
+// RunSnake drafts every roster spot in a snake draft, each round picking in
+// the reverse order of the last
+func (d *Draft) RunSnake() error {
+	for round := range d.RosterSize() {
+		order := slices.Clone(d.Teams)
+		if round%2 == 1 {
+			slices.Reverse(order)
+		}
+		for _, team := range order {
+			if len(d.Draftable(team)) == 0 {
+				continue
+			}
+			player, err := team.Strategy.Pick(d, team)
+			if err != nil {
+				return err
+			}
+			if err := d.draft(team, player, round+1, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RunAuction drafts every roster spot in an auction. Teams take turns
+// nominating players, opening the bidding at $1, and each nominee goes to the
+// highest bidder for a dollar more than the next highest bid. A nominator
+// can't pass on its own nominee, so bids it less than $1 count as $1.
+func (d *Draft) RunAuction() error {
+	nominator := 0
+	for {
+		// the next team with room on its roster nominates
+		var team *Team
+		for range d.Teams {
+			candidate := d.Teams[nominator%len(d.Teams)]
+			nominator++
+			if len(d.Draftable(candidate)) > 0 {
+				team = candidate
+				break
+			}
+		}
+		if team == nil {
+			return nil
+		}
+
+		player, err := team.Strategy.Pick(d, team)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(d.Available, player) || !d.CanDraft(team, player) {
+			return fmt.Errorf("%s can't nominate %s", team.Name, player.Name)
+		}
+
+		// bid in nomination order so ties go to the nominator
+		winner, high, second := team, 1, 0
+		for i := range d.Teams {
+			bidder := d.Teams[(nominator-1+i)%len(d.Teams)]
+			if !d.CanDraft(bidder, player) {
+				continue
+			}
+			bid, err := bidder.Strategy.MaxBid(d, bidder, player)
+			if err != nil {
+				return err
+			}
+			bid = min(bid, d.MaxAffordable(bidder))
+			if bidder == team {
+				bid = max(bid, 1)
+			}
+			if bid > high || (bid == high && bidder == team) {
+				if bidder != winner {
+					second = high
+				}
+				winner, high = bidder, bid
+			} else {
+				second = max(second, bid)
+			}
+		}
+		price := max(min(high, second+1), 1)
+		if err := d.draft(winner, player, len(winner.Roster)+1, price); err != nil {
+			return err
+		}
+	}
+}
+
+// MaxAffordable is the most a team can bid while keeping $1 for each of its
+// other open roster spots
+func (d *Draft) MaxAffordable(team *Team) int {
+	return team.Budget - (d.RosterSize() - len(team.Roster) - 1)
+}
+
+func (d *Draft) draft(team *Team, player *Player, round int, price int) error {
+	index := slices.Index(d.Available, player)
+	if index < 0 {
+		return fmt.Errorf("%s picked %s, who isn't available", team.Name, player.Name)
+	}
+	if !d.CanDraft(team, player) {
+		return fmt.Errorf("%s has no room for %s", team.Name, player.Name)
+	}
+	d.Available = slices.Delete(d.Available, index, index+1)
+	team.Roster = append(team.Roster, player)
+	team.Budget -= price
+	d.Picks = append(d.Picks, Pick{Number: len(d.Picks) + 1, Round: round, Team: team, Player: player, Price: price})
+	return nil
+}
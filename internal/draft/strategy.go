@@ -0,0 +1,115 @@
+package draft
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Strategy decides a team's picks and bids
+type Strategy interface {
+	// Pick chooses a player to draft, or to nominate in an auction
+	Pick(d *Draft, team *Team) (*Player, error)
+	// MaxBid is the most the team will pay for a player in an auction
+	MaxBid(d *Draft, team *Team, player *Player) (int, error)
+}
+
+// DEFAULT_ADP_NOISE is the standard deviation, in draft ranks, of how far an
+// ADP following team strays from the rankings
+const DEFAULT_ADP_NOISE = 3.0
+
+// DEFAULT_ADP_BID_NOISE is the standard deviation, as a fraction of auction
+// value, of how far an ADP following team's bids stray from value
+const DEFAULT_ADP_BID_NOISE = 0.15
+
+// STRATEGIES are the bot strategies teams can draft with, by name
+var STRATEGIES = map[string]func() Strategy{
+	"best": func() Strategy { return BestAvailable{} },
+	"need": func() Strategy { return PositionalNeed{} },
+	"adp":  func() Strategy { return ADP{Noise: DEFAULT_ADP_NOISE, BidNoise: DEFAULT_ADP_BID_NOISE} },
+}
+
+// NewStrategy looks up a bot strategy by name
+func NewStrategy(name string) (Strategy, error) {
+	newStrategy, exists := STRATEGIES[strings.ToLower(name)]
+	if !exists {
+		return nil, fmt.Errorf("unknown draft strategy: %s", name)
+	}
+	return newStrategy(), nil
+}
+
+// BestAvailable takes the player with the most value over replacement it has
+// room for, and bids their auction value
+type BestAvailable struct{}
+
+func (BestAvailable) Pick(d *Draft, team *Team) (*Player, error) {
+	return mostValuable(d.Draftable(team)), nil
+}
+
+func (BestAvailable) MaxBid(d *Draft, team *Team, player *Player) (int, error) {
+	return player.Value, nil
+}
+
+// PositionalNeed fills its starting lineup before its bench, taking the most
+// valuable player at a position it still needs to start. It bids up players
+// that fill a need and bids low on the rest.
+type PositionalNeed struct{}
+
+func (PositionalNeed) Pick(d *Draft, team *Team) (*Player, error) {
+	draftable := d.Draftable(team)
+	var needed []*Player
+	for _, player := range draftable {
+		if d.FillsStarter(team, player) {
+			needed = append(needed, player)
+		}
+	}
+	if len(needed) > 0 {
+		return mostValuable(needed), nil
+	}
+	return mostValuable(draftable), nil
+}
+
+func (PositionalNeed) MaxBid(d *Draft, team *Team, player *Player) (int, error) {
+	if d.FillsStarter(team, player) {
+		return int(math.Round(float64(player.Value) * 1.2)), nil
+	}
+	return player.Value / 2, nil
+}
+
+// ADP follows the draft rankings with some noise, like drafters taking
+// players around their average draft position
+type ADP struct {
+	// Noise is the standard deviation, in draft ranks, of each pick
+	Noise float64
+	// BidNoise is the standard deviation, as a fraction of auction value, of
+	// each bid
+	BidNoise float64
+}
+
+func (s ADP) Pick(d *Draft, team *Team) (*Player, error) {
+	var best *Player
+	bestRank := math.Inf(1)
+	for _, player := range d.Draftable(team) {
+		rank := float64(player.Rank) + d.Rng.NormFloat64()*s.Noise
+		if rank < bestRank {
+			best, bestRank = player, rank
+		}
+	}
+	return best, nil
+}
+
+func (s ADP) MaxBid(d *Draft, team *Team, player *Player) (int, error) {
+	return int(math.Round(float64(player.Value) * (1 + d.Rng.NormFloat64()*s.BidNoise))), nil
+}
+
+func mostValuable(players []*Player) *Player {
+	if len(players) == 0 {
+		return nil
+	}
+	// ties go to the better ranked player
+	return slices.MaxFunc(players, func(i, j *Player) int {
+		return cmp.Or(cmp.Compare(i.VOR, j.VOR), cmp.Compare(j.Rank, i.Rank))
+	})
+}
@@ -0,0 +1,216 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/boldandbrad/fffetch/internal/util"
+)
+
+// DEFAULT_MANIFEST_PATH is where the state of every task is kept
+var DEFAULT_MANIFEST_PATH = "output/manifest.json"
+
+// Stage is a step of processing a team's season
+type Stage string
+
+const (
+	StageFetched    Stage = "fetched"
+	StageParsed     Stage = "parsed"
+	StageCalculated Stage = "calculated"
+	StageWritten    Stage = "written"
+)
+
+// STAGES are the stages of a task, in the order they run
+var STAGES = []Stage{StageFetched, StageParsed, StageCalculated, StageWritten}
+
+// ParseStage looks up a stage by name
+func ParseStage(name string) (Stage, error) {
+	stage := Stage(name)
+	if !slices.Contains(STAGES, stage) {
+		return "", fmt.Errorf("unknown stage: %s (expected one of fetched, parsed, calculated or written)", name)
+	}
+	return stage, nil
+}
+
+// Before reports whether a stage runs before another
+func (s Stage) Before(other Stage) bool {
+	return slices.Index(STAGES, s) < slices.Index(STAGES, other)
+}
+
+// StageRecord records when a stage last completed and the checksums of the
+// files it produced, by path
+type StageRecord struct {
+	At    time.Time         `json:"at"`
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// Task is the state of one team's season
+type Task struct {
+	Team   string                `json:"team"`
+	Year   int                   `json:"year"`
+	Stage  Stage                 `json:"stage"`
+	Stages map[Stage]StageRecord `json:"stages"`
+}
+
+// Manifest tracks how far each task has gotten, saving itself after every
+// change so an interrupted run can resume
+type Manifest struct {
+	path  string
+	mu    sync.Mutex
+	Tasks map[string]*Task `json:"tasks"`
+}
+
+// Load reads the manifest at path, or starts an empty one if there is none
+func Load(path string) (*Manifest, error) {
+	m := &Manifest{path: path, Tasks: map[string]*Task{}}
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(contents, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	if m.Tasks == nil {
+		m.Tasks = map[string]*Task{}
+	}
+	return m, nil
+}
+
+func taskKey(team string, year int) string {
+	return fmt.Sprintf("%s_%d", team, year)
+}
+
+// Completed reports whether a stage of a task has been recorded
+func (m *Manifest) Completed(team string, year int, stage Stage) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, exists := m.Tasks[taskKey(team, year)]
+	if !exists {
+		return false
+	}
+	_, exists = task.Stages[stage]
+	return exists
+}
+
+// Resume returns the first stage of a task that hasn't completed, or whose
+// files have since gone missing or changed, and whether there is one
+func (m *Manifest) Resume(team string, year int) (Stage, bool) {
+	m.mu.Lock()
+	task := m.Tasks[taskKey(team, year)]
+	var records []StageRecord
+	for _, stage := range STAGES {
+		record, exists := StageRecord{}, false
+		if task != nil {
+			record, exists = task.Stages[stage]
+		}
+		if !exists {
+			m.mu.Unlock()
+			return stage, true
+		}
+		records = append(records, record)
+	}
+	m.mu.Unlock()
+
+	// verify files outside the lock, hashing them can take a while
+	for i, record := range records {
+		for path, checksum := range record.Files {
+			if actual, err := ChecksumFile(path); err != nil || actual != checksum {
+				return STAGES[i], true
+			}
+		}
+	}
+	return "", false
+}
+
+// record marks a stage of a task completed, clearing any later stages, and
+// saves the manifest
+func (m *Manifest) record(team string, year int, stage Stage, record StageRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := taskKey(team, year)
+	task, exists := m.Tasks[key]
+	if !exists {
+		task = &Task{Team: team, Year: year, Stages: map[Stage]StageRecord{}}
+		m.Tasks[key] = task
+	}
+	for _, later := range STAGES[slices.Index(STAGES, stage)+1:] {
+		delete(task.Stages, later)
+	}
+	record.At = time.Now().UTC()
+	task.Stages[stage] = record
+	task.Stage = stage
+	return m.save()
+}
+
+// RecordFiles marks a stage of a task completed, producing the given files
+func (m *Manifest) RecordFiles(team string, year int, stage Stage, paths ...string) error {
+	checksums := map[string]string{}
+	for _, path := range paths {
+		checksum, err := ChecksumFile(path)
+		if err != nil {
+			return err
+		}
+		checksums[path] = checksum
+	}
+	return m.record(team, year, stage, StageRecord{Files: checksums})
+}
+
+// RefreshFile updates the checksum of a file changed outside of its task,
+// such as a final file given projections, and saves the manifest
+func (m *Manifest) RefreshFile(path string) error {
+	checksum, err := ChecksumFile(path)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	changed := false
+	for _, task := range m.Tasks {
+		for _, record := range task.Stages {
+			if _, exists := record.Files[path]; exists {
+				record.Files[path] = checksum
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return m.save()
+}
+
+func (m *Manifest) save() error {
+	contents, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFile(m.path, string(contents)+"\n")
+}
+
+// ChecksumFile returns the SHA-256 checksum of a file's contents
+func ChecksumFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,108 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestManifest starts an empty manifest with a page saved in a temp dir
+func newTestManifest(t *testing.T) (*Manifest, string) {
+	t.Helper()
+	dir := t.TempDir()
+	m, err := Load(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := filepath.Join(dir, "KC_2024.htm")
+	if err := os.WriteFile(page, []byte("page"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return m, page
+}
+
+// recordAll completes every stage of a task, the first producing page
+func recordAll(t *testing.T, m *Manifest, page string) {
+	t.Helper()
+	if err := m.RecordFiles("KC", 2024, StageFetched, page); err != nil {
+		t.Fatal(err)
+	}
+	for _, stage := range STAGES[1:] {
+		if err := m.RecordFiles("KC", 2024, stage); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResumeCompleted(t *testing.T) {
+	m, page := newTestManifest(t)
+	recordAll(t, m, page)
+
+	if stage, pending := m.Resume("KC", 2024); pending {
+		t.Errorf("Resume = %s, want nothing pending", stage)
+	}
+	if stage, pending := m.Resume("BUF", 2024); !pending || stage != StageFetched {
+		t.Errorf("Resume of unknown task = %s, %v, want %s", stage, pending, StageFetched)
+	}
+}
+
+func TestResumeMissingFile(t *testing.T) {
+	m, page := newTestManifest(t)
+	recordAll(t, m, page)
+	if err := os.Remove(page); err != nil {
+		t.Fatal(err)
+	}
+
+	if stage, pending := m.Resume("KC", 2024); !pending || stage != StageFetched {
+		t.Errorf("Resume = %s, %v, want %s", stage, pending, StageFetched)
+	}
+}
+
+func TestResumeChangedChecksum(t *testing.T) {
+	m, page := newTestManifest(t)
+	recordAll(t, m, page)
+	if err := os.WriteFile(page, []byte("changed page"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if stage, pending := m.Resume("KC", 2024); !pending || stage != StageFetched {
+		t.Errorf("Resume = %s, %v, want %s", stage, pending, StageFetched)
+	}
+
+	// a file refreshed after an intended change is trusted again
+	if err := m.RefreshFile(page); err != nil {
+		t.Fatal(err)
+	}
+	if stage, pending := m.Resume("KC", 2024); pending {
+		t.Errorf("Resume after refresh = %s, want nothing pending", stage)
+	}
+}
+
+func TestRecordClearsLaterStages(t *testing.T) {
+	m, page := newTestManifest(t)
+	recordAll(t, m, page)
+
+	if err := m.RecordFiles("KC", 2024, StageParsed); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Completed("KC", 2024, StageFetched) || !m.Completed("KC", 2024, StageParsed) {
+		t.Error("earlier stages cleared, want them kept")
+	}
+	for _, stage := range []Stage{StageCalculated, StageWritten} {
+		if m.Completed("KC", 2024, stage) {
+			t.Errorf("%s still completed, want it cleared", stage)
+		}
+	}
+	if stage, pending := m.Resume("KC", 2024); !pending || stage != StageCalculated {
+		t.Errorf("Resume = %s, %v, want %s", stage, pending, StageCalculated)
+	}
+
+	// the saved manifest agrees
+	loaded, err := Load(m.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task := loaded.Tasks[taskKey("KC", 2024)]; task.Stage != StageParsed || len(task.Stages) != 2 {
+		t.Errorf("saved task at %s with %d stages, want %s with 2", task.Stage, len(task.Stages), StageParsed)
+	}
+}
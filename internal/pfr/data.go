@@ -36,12 +36,84 @@ var PFR_TEAM_KEYS = map[string]string{
 	"WSH": "was",
 }
 
+// Pro Football Reference team abbreviations, as shown in player game logs
+var PFR_TEAM_ABBRS = map[string]string{
+	"ARI": "ARI",
+	"ATL": "ATL",
+	"BAL": "BAL",
+	"BUF": "BUF",
+	"CAR": "CAR",
+	"CHI": "CHI",
+	"CIN": "CIN",
+	"CLE": "CLE",
+	"DAL": "DAL",
+	"DEN": "DEN",
+	"DET": "DET",
+	"GB":  "GNB",
+	"HOU": "HOU",
+	"IND": "IND",
+	"JAX": "JAX",
+	"KC":  "KAN",
+	"LAC": "LAC",
+	"LAR": "LAR",
+	"LVR": "LVR",
+	"MIA": "MIA",
+	"MIN": "MIN",
+	"NO":  "NOR",
+	"NE":  "NWE",
+	"NYG": "NYG",
+	"NYJ": "NYJ",
+	"PHI": "PHI",
+	"PIT": "PIT",
+	"SEA": "SEA",
+	"SF":  "SFO",
+	"TB":  "TAM",
+	"TEN": "TEN",
+	"WSH": "WAS",
+}
+
+// Pro Football Reference abbreviations of teams before they relocated, and
+// the last season each was used
+var PFR_FORMER_TEAM_ABBRS = map[string]struct {
+	Abbr    string
+	Through int
+}{
+	"LVR": {Abbr: "OAK", Through: 2019},
+	"LAC": {Abbr: "SDG", Through: 2016},
+	"LAR": {Abbr: "STL", Through: 2015},
+}
+
+// TeamAbbr returns a team's abbreviation in a year's player game logs
+func TeamAbbr(team string, year int) string {
+	if former, exists := PFR_FORMER_TEAM_ABBRS[team]; exists && year <= former.Through {
+		return former.Abbr
+	}
+	return PFR_TEAM_ABBRS[team]
+}
+
 // Pro Football Reference table ids
 var PFR_TABLE_IDS = []string{
 	"passing",
 	"rushing_and_receiving",
+	"kicking",
+	"returns",
 }
 
+// Pro Football Reference table ids used to score team defense/special teams
+var PFR_DST_TABLE_IDS = []string{
+	"games",
+	"defense",
+	"returns",
+}
+
+// Pro Football Reference table ids used to score individual defensive players
+var PFR_IDP_TABLE_IDS = []string{
+	"defense",
+}
+
+// Pro Football Reference player game log table id
+var PFR_GAMELOG_TABLE_ID = "stats"
+
 // Pro Football Reference table headers to rename
 var HEADER_RENAMES = map[string]string{
 	"name_display":    "player",
@@ -51,4 +123,18 @@ var HEADER_RENAMES = map[string]string{
 	"rec_first_down":  "rec_1d",
 	"pass_first_down": "pass_1d",
 	"pass_sacked":     "times sacked",
+	"fga1":            "fga_0_19",
+	"fgm1":            "fgm_0_19",
+	"fga2":            "fga_20_29",
+	"fgm2":            "fgm_20_29",
+	"fga3":            "fga_30_39",
+	"fgm3":            "fgm_30_39",
+	"fga4":            "fga_40_49",
+	"fgm4":            "fgm_40_49",
+	"fga5":            "fga_50_plus",
+	"fgm5":            "fgm_50_plus",
+	"week_num":        "week",
+	"team_name_abbr":  "team",
+	"opp_name_abbr":   "opp",
+	"game_result":     "result",
 }
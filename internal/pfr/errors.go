@@ -0,0 +1,57 @@
+package pfr
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrRateLimited = errors.New("Pro Football Reference rate limit hit")
+	ErrNotFound    = errors.New("page not found")
+	// ErrTableNotFound is wrapped by a ParseError for a page missing a table
+	ErrTableNotFound = errors.New("table not found")
+)
+
+// StatusError reports an unsuccessful response from Pro Football Reference
+type StatusError struct {
+	URL        string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	switch e.StatusCode {
+	case 429:
+		return fmt.Sprintf("%v: %s", ErrRateLimited, e.URL)
+	case 404:
+		return fmt.Sprintf("%v: %s", ErrNotFound, e.URL)
+	default:
+		return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.URL)
+	}
+}
+
+func (e *StatusError) Unwrap() error {
+	switch e.StatusCode {
+	case 429:
+		return ErrRateLimited
+	case 404:
+		return ErrNotFound
+	default:
+		return nil
+	}
+}
+
+// ParseError reports a fetched page that could not be parsed
+type ParseError struct {
+	Path string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse %s: %v", e.Path, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,116 @@
+package pfr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var PFR_BASE_URL = "https://www.pro-football-reference.com"
+
+var DEFAULT_USER_AGENT = "fffetch (+https://github.com/boldandbrad/fffetch)"
+
+// RetryFunc is called before waiting to retry a failed request
+type RetryFunc func(attempt int, wait time.Duration, err error)
+
+// Fetcher retrieves pages from Pro Football Reference or a stand-in for it
+type Fetcher struct {
+	Client    *http.Client
+	BaseURL   string
+	UserAgent string
+	Limiter   Limiter
+	Retry     RetryPolicy
+}
+
+func NewFetcher(client *http.Client, baseURL string, userAgent string, limiter Limiter) *Fetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if baseURL == "" {
+		baseURL = PFR_BASE_URL
+	}
+	if userAgent == "" {
+		userAgent = DEFAULT_USER_AGENT
+	}
+	return &Fetcher{
+		Client:    client,
+		BaseURL:   strings.TrimSuffix(baseURL, "/"),
+		UserAgent: userAgent,
+		Limiter:   limiter,
+		Retry:     DefaultRetryPolicy,
+	}
+}
+
+// FetchTeamPage fetches a team's season page
+func (f *Fetcher) FetchTeamPage(ctx context.Context, teamKey string, year int, onRetry RetryFunc) (string, error) {
+	return f.FetchPage(ctx, fmt.Sprintf("/teams/%s/%d.htm", teamKey, year), onRetry)
+}
+
+// FetchPage fetches the page at path relative to the base URL, retrying
+// failures according to the fetcher's retry policy. Canceling ctx aborts the
+// request and any wait before it.
+func (f *Fetcher) FetchPage(ctx context.Context, path string, onRetry RetryFunc) (string, error) {
+	url := f.BaseURL + path
+
+	for attempt := 1; ; attempt++ {
+		page, err := f.get(ctx, url)
+		if err == nil {
+			return page, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if attempt > f.Retry.MaxRetries || !retryable(err) {
+			return "", err
+		}
+
+		wait := f.Retry.backoff(attempt, err)
+		if onRetry != nil {
+			onRetry(attempt, wait, err)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (f *Fetcher) get(ctx context.Context, url string) (string, error) {
+	if f.Limiter != nil {
+		if err := f.Limiter.Wait(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", f.UserAgent)
+
+	res, err := f.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return "", &StatusError{
+			URL:        url,
+			StatusCode: res.StatusCode,
+			RetryAfter: parseRetryAfter(res.Header.Get("Retry-After")),
+		}
+	}
+
+	bodyBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(bodyBytes), nil
+}
@@ -0,0 +1,141 @@
+package pfr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  time.Millisecond,
+	MaxDelay:   10 * time.Millisecond,
+}
+
+// newTestFetcher serves each request with handler, counting the requests made
+func newTestFetcher(t *testing.T, handler http.HandlerFunc) (*Fetcher, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	fetcher := NewFetcher(server.Client(), server.URL+"/", "", nil)
+	fetcher.Retry = testRetryPolicy
+	return fetcher, &requests
+}
+
+func TestFetchPageUserAgentAndBaseURL(t *testing.T) {
+	fetcher, _ := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/teams/kan/2024.htm" {
+			t.Errorf("path = %s, want /teams/kan/2024.htm", r.URL.Path)
+		}
+		if got := r.Header.Get("User-Agent"); got != DEFAULT_USER_AGENT {
+			t.Errorf("User-Agent = %q, want %q", got, DEFAULT_USER_AGENT)
+		}
+		w.Write([]byte("page"))
+	})
+
+	page, err := fetcher.FetchTeamPage(context.Background(), "kan", 2024, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page != "page" {
+		t.Errorf("page = %q, want %q", page, "page")
+	}
+}
+
+func TestFetchPageNotFoundNotRetried(t *testing.T) {
+	fetcher, requests := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := fetcher.FetchPage(context.Background(), "/missing.htm", nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want %v", err, ErrNotFound)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestFetchPageRateLimitedWaitsRetryAfter(t *testing.T) {
+	var limited atomic.Bool
+	fetcher, _ := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		if !limited.Swap(true) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("page"))
+	})
+	fetcher.Retry.MaxDelay = time.Minute
+
+	var waits []time.Duration
+	page, err := fetcher.FetchPage(context.Background(), "/page.htm", func(attempt int, wait time.Duration, err error) {
+		if !errors.Is(err, ErrRateLimited) {
+			t.Errorf("retry err = %v, want %v", err, ErrRateLimited)
+		}
+		waits = append(waits, wait)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page != "page" {
+		t.Errorf("page = %q, want %q", page, "page")
+	}
+	if len(waits) != 1 || waits[0] != time.Second {
+		t.Errorf("waits = %v, want [1s]", waits)
+	}
+}
+
+func TestFetchPageServerErrorRetried(t *testing.T) {
+	fetcher, requests := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	var attempts []int
+	_, err := fetcher.FetchPage(context.Background(), "/page.htm", func(attempt int, wait time.Duration, err error) {
+		attempts = append(attempts, attempt)
+	})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("err = %v, want status %d", err, http.StatusBadGateway)
+	}
+	if want := int32(testRetryPolicy.MaxRetries + 1); requests.Load() != want {
+		t.Errorf("requests = %d, want %d", requests.Load(), want)
+	}
+	if len(attempts) != testRetryPolicy.MaxRetries {
+		t.Errorf("retries = %v, want %d", attempts, testRetryPolicy.MaxRetries)
+	}
+}
+
+func TestFetchPageCancelAbortsWait(t *testing.T) {
+	fetcher, requests := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	fetcher.Retry.MaxDelay = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := time.Now()
+	_, err := fetcher.FetchPage(ctx, "/page.htm", func(attempt int, wait time.Duration, err error) {
+		cancel()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("canceled fetch took %v", elapsed)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
@@ -3,62 +3,113 @@ package pfr
 import (
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
-	"io"
-	"log"
-	"net/http"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/boldandbrad/fffetch/internal/util"
 )
 
-var PFR_URL = "https://www.pro-football-reference.com/teams"
+var commentPattern = regexp.MustCompile(`(?s)<!--(.*?)-->`)
 
-func FetchPage(teamKey string, year int) string {
-	url := fmt.Sprintf("%s/%s/%d.htm", PFR_URL, teamKey, year)
-	res, err := http.Get(url)
+func ParsePage(filePath string, tableIDs []string) ([]util.Table, error) {
+	doc, err := loadPage(filePath)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	defer res.Body.Close()
-
-	resStatus := res.StatusCode
-	if resStatus != 200 {
-		if resStatus == 429 {
-			log.Fatal("Pro Football Reference Rate limit hit, please try again later.")
-		} else if resStatus == 404 {
-			log.Fatalf("Page not found for %s %d", teamKey, year)
-		} else {
-			log.Fatalf("Unknown status code %d for %s %d", resStatus, teamKey, year)
+
+	var tables []util.Table
+	for _, tableid := range tableIDs {
+		table := parseTable(doc, tableid)
+		if len(table.Headers) == 0 {
+			return nil, &ParseError{Path: filePath, Err: fmt.Errorf("%w: %s", ErrTableNotFound, tableid)}
 		}
+		tables = append(tables, table)
 	}
+	return tables, nil
+}
 
-	bodyBytes, err := io.ReadAll(res.Body)
+// PlayerLink is a player listed on a team page
+type PlayerLink struct {
+	ID   string
+	Name string
+	Pos  string
+}
+
+// GameLogPath returns the path to the player's game log for a season
+func (l PlayerLink) GameLogPath(year int) string {
+	return fmt.Sprintf("/players/%s/%s/gamelog/%d/", l.ID[:1], l.ID, year)
+}
+
+// ParsePlayerLinks finds the players listed in the given tables of a page
+func ParsePlayerLinks(filePath string, tableIDs []string) ([]PlayerLink, error) {
+	doc, err := loadPage(filePath)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	return string(bodyBytes)
+
+	var links []PlayerLink
+	seen := map[string]bool{}
+	for _, tableid := range tableIDs {
+		doc.Find(fmt.Sprintf("#%s", tableid)).First().Find("tbody tr").Each(func(_ int, rsel *goquery.Selection) {
+			id := playerID(rsel)
+			if id != "" && !seen[id] {
+				seen[id] = true
+				links = append(links, PlayerLink{
+					ID:   id,
+					Name: rsel.Find("td[data-stat='name_display']").Text(),
+					Pos:  rsel.Find("td[data-stat='pos']").Text(),
+				})
+			}
+		})
+	}
+	return links, nil
 }
 
-func ParsePage(filePath string) []util.Table {
-	// read file into memory
-	file, err := os.Open(filePath)
+// ParseGameLog parses the weekly rows of a player's game log page
+func ParseGameLog(filePath string) (util.Table, error) {
+	doc, err := loadPage(filePath)
 	if err != nil {
-		log.Fatal(err)
+		return util.Table{}, err
 	}
-	defer file.Close()
 
-	doc, err := goquery.NewDocumentFromReader(file)
+	// drop games the player was inactive or did not play in
+	doc.Find(fmt.Sprintf("#%s tbody tr", PFR_GAMELOG_TABLE_ID)).Has("td[data-stat='reason']").Remove()
+
+	table := parseTable(doc, PFR_GAMELOG_TABLE_ID)
+	if len(table.Headers) == 0 {
+		return util.Table{}, &ParseError{Path: filePath, Err: fmt.Errorf("%w: %s", ErrTableNotFound, PFR_GAMELOG_TABLE_ID)}
+	}
+	table.Name = "gamelog"
+	return table, nil
+}
+
+// loadPage reads a fetched page into a document, including commented tables
+func loadPage(filePath string) (*goquery.Document, error) {
+	// read file into memory
+	pageBytes, err := os.ReadFile(filePath)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	var tables []util.Table
-	for _, tableid := range PFR_TABLE_IDS {
-		table := parseTable(doc, tableid)
-		tables = append(tables, table)
+	page := uncommentTables(string(pageBytes))
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return nil, &ParseError{Path: filePath, Err: err}
 	}
-	return tables
+	return doc, nil
+}
+
+// uncommentTables unwraps table markup that Pro Football Reference hides
+// inside HTML comments and renders client-side
+func uncommentTables(page string) string {
+	return commentPattern.ReplaceAllStringFunc(page, func(comment string) string {
+		inner := commentPattern.FindStringSubmatch(comment)[1]
+		if strings.Contains(inner, "<table") {
+			return inner
+		}
+		return comment
+	})
 }
 
 func parseTable(doc *goquery.Document, tableid string) util.Table {
@@ -67,13 +118,16 @@ func parseTable(doc *goquery.Document, tableid string) util.Table {
 
 	doc.Find(fmt.Sprintf("#%s", tableid)).Each(func(i int, tsel *goquery.Selection) {
 		if i == 0 {
-			// loop through headers
-			tsel.Find("th").Each(func(_ int, hsel *goquery.Selection) {
+			// loop through headers, remembering each stat's column
+			columns := map[string]int{}
+			tsel.Find("thead").Find("th").Each(func(_ int, hsel *goquery.Selection) {
 				if hsel != nil {
-					header, exists := hsel.Attr("data-stat")
-					if exists && header != "ranker" && !strings.Contains(header, "header") {
+					stat, exists := hsel.Attr("data-stat")
+					if exists && stat != "ranker" && !strings.Contains(stat, "header") {
+						columns[stat] = len(table.Headers)
 						// rename headers
-						if headerNewName, exists := HEADER_RENAMES[header]; exists {
+						header := stat
+						if headerNewName, exists := HEADER_RENAMES[stat]; exists {
 							header = headerNewName
 						}
 						table.Headers = append(table.Headers, header)
@@ -81,30 +135,45 @@ func parseTable(doc *goquery.Document, tableid string) util.Table {
 				}
 			})
 
-			// loop through rows
-			tsel.Find("tbody").Find("tr").Each(func(index int, rsel *goquery.Selection) {
-				var row []string
-
-				// loop through cells
-				rsel.Find("td").Each(func(_ int, csel *goquery.Selection) {
-					if csel != nil {
-						row = append(row, csel.Text())
-					}
-				})
+			// players are identified by the id appended to their name cell
+			_, hasPlayers := columns["name_display"]
+			if hasPlayers {
+				table.Headers = append(table.Headers, "player_id")
+			}
+
+			// loop through rows, skipping repeated header rows
+			tsel.Find("tbody").Find("tr").Not(".thead").Each(func(index int, rsel *goquery.Selection) {
+				row := parseRow(rsel, columns, len(table.Headers))
+				if hasPlayers {
+					row[len(row)-1] = playerID(rsel)
+				}
 				table.Rows = append(table.Rows, row)
 			})
 
 			// grab footer row
-			var footerRow []string
-			tsel.Find("tfoot").Find("tr").Find("td").Each(func(_ int, csel *goquery.Selection) {
-				if csel != nil {
-					footerRow = append(footerRow, csel.Text())
-				}
+			tsel.Find("tfoot").Find("tr").First().Each(func(_ int, rsel *goquery.Selection) {
+				table.FooterRow = parseRow(rsel, columns, len(table.Headers))
 			})
-
-			table.FooterRow = footerRow
 		}
 	})
 
 	return table
 }
+
+// playerID returns the Pro Football Reference id of a row's player
+func playerID(rsel *goquery.Selection) string {
+	id, _ := rsel.Find("[data-stat='name_display']").Attr("data-append-csv")
+	return id
+}
+
+// parseRow places each cell of a row in its stat's column
+func parseRow(rsel *goquery.Selection, columns map[string]int, width int) []string {
+	row := make([]string, width)
+	rsel.Find("th, td").Each(func(_ int, csel *goquery.Selection) {
+		stat, _ := csel.Attr("data-stat")
+		if column, exists := columns[stat]; exists {
+			row[column] = csel.Text()
+		}
+	})
+	return row
+}
@@ -0,0 +1,38 @@
+package pfr
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DEFAULT_RATE is the requests per minute Pro Football Reference allows
+// before blocking a client
+var DEFAULT_RATE = 20.0
+
+// Limiter spaces out requests to avoid rate limiting
+type Limiter interface {
+	// Wait blocks until a request is allowed, or ctx is canceled
+	Wait(ctx context.Context) error
+}
+
+// TokenBucketLimiter allows requests at a steady rate, however many workers
+// share it
+type TokenBucketLimiter struct {
+	limiter *rate.Limiter
+}
+
+// NewTokenBucketLimiter allows perMinute requests a minute, one at a time. A
+// rate of zero or less allows requests as fast as they are made.
+func NewTokenBucketLimiter(perMinute float64) *TokenBucketLimiter {
+	limit := rate.Inf
+	if perMinute > 0 {
+		limit = rate.Every(time.Duration(float64(time.Minute) / perMinute))
+	}
+	return &TokenBucketLimiter{limiter: rate.NewLimiter(limit, 1)}
+}
+
+func (l *TokenBucketLimiter) Wait(ctx context.Context) error {
+	return l.limiter.Wait(ctx)
+}
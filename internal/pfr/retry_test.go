@@ -0,0 +1,91 @@
+package pfr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", &StatusError{StatusCode: http.StatusBadGateway}, true},
+		{"not found", &StatusError{StatusCode: http.StatusNotFound}, false},
+		{"connection refused", &url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{"truncated body", fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), true},
+		{"closed connection", &url.Error{Op: "Get", URL: "http://x", Err: io.EOF}, true},
+		{"malformed url", &url.Error{Op: "parse", URL: "://x", Err: errors.New("missing protocol scheme")}, false},
+		{"canceled", context.Canceled, false},
+	}
+	for _, test := range tests {
+		if got := retryable(test.err); got != test.want {
+			t.Errorf("retryable(%s) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}
+
+	if got := policy.backoff(1, &StatusError{StatusCode: 429, RetryAfter: 30 * time.Second}); got != 30*time.Second {
+		t.Errorf("backoff = %v, want 30s", got)
+	}
+	if got := policy.backoff(1, &StatusError{StatusCode: 429, RetryAfter: time.Hour}); got != time.Minute {
+		t.Errorf("backoff = %v, want the 1m maximum", got)
+	}
+}
+
+func TestBackoffExponential(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	tests := []struct {
+		attempt int
+		delay   time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{5, 10 * time.Second},
+		{64, 10 * time.Second},
+	}
+	for _, test := range tests {
+		// jitter keeps each wait between half and all of the delay
+		for range 20 {
+			got := policy.backoff(test.attempt, errors.New("connection reset"))
+			if got < test.delay/2 || got > test.delay {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", test.attempt, got, test.delay/2, test.delay)
+			}
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("120"); got != 2*time.Minute {
+		t.Errorf("seconds = %v, want 2m", got)
+	}
+
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(date); got <= 59*time.Minute || got > time.Hour {
+		t.Errorf("date = %v, want about 1h", got)
+	}
+
+	past := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(past); got > 0 {
+		t.Errorf("past date = %v, want no wait", got)
+	}
+
+	for _, value := range []string{"", "soon"} {
+		if got := parseRetryAfter(value); got != 0 {
+			t.Errorf("parseRetryAfter(%q) = %v, want 0", value, got)
+		}
+	}
+}
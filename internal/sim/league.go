@@ -0,0 +1,119 @@
+package sim
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/boldandbrad/fffetch/internal/calc"
+)
+
+// DEFAULT_WEEKS is the length of a generated regular season schedule
+const DEFAULT_WEEKS = 14
+
+// DEFAULT_PLAYOFF_TEAMS is how many teams make the playoffs by default
+const DEFAULT_PLAYOFF_TEAMS = 4
+
+// Team is a fantasy team and the players on its roster, by Pro Football
+// Reference player id or name
+type Team struct {
+	Name   string   `mapstructure:"name"`
+	Roster []string `mapstructure:"roster"`
+}
+
+// League is a fantasy league to simulate
+type League struct {
+	// Year is the season whose weekly stats players are drawn from
+	Year    int    `mapstructure:"year"`
+	Scoring string `mapstructure:"scoring"`
+	// Slots are the starters each team plays at each position or flex slot
+	Slots map[string]int `mapstructure:"slots"`
+	Teams []Team         `mapstructure:"teams"`
+	// Schedule lists each week's matchups as pairs of team names. A round
+	// robin schedule of Weeks weeks is generated when it is empty.
+	Schedule     [][][]string `mapstructure:"schedule"`
+	Weeks        int          `mapstructure:"weeks"`
+	PlayoffTeams int          `mapstructure:"playoff_teams"`
+}
+
+// Validate checks the league's teams, schedule and playoffs make sense,
+// generating a schedule and lineup if there isn't one
+func (l *League) Validate() error {
+	if len(l.Slots) == 0 {
+		l.Slots = calc.DEFAULT_LEAGUE.Clone().Slots
+	}
+	l.Slots = calc.NormalizeSlots(l.Slots)
+
+	if len(l.Teams) < 2 {
+		return fmt.Errorf("league needs at least 2 teams, found %d", len(l.Teams))
+	}
+	var names []string
+	for _, team := range l.Teams {
+		if team.Name == "" {
+			return fmt.Errorf("league team is missing a name")
+		}
+		if slices.Contains(names, team.Name) {
+			return fmt.Errorf("league has more than one team named %s", team.Name)
+		}
+		names = append(names, team.Name)
+	}
+
+	if len(l.Schedule) == 0 {
+		if l.Weeks == 0 {
+			l.Weeks = DEFAULT_WEEKS
+		}
+		l.Schedule = roundRobin(names, l.Weeks)
+	}
+	for week, matchups := range l.Schedule {
+		for _, matchup := range matchups {
+			if len(matchup) != 2 {
+				return fmt.Errorf("week %d matchup %s must have 2 teams", week+1, strings.Join(matchup, " vs "))
+			}
+			for _, name := range matchup {
+				if !slices.Contains(names, name) {
+					return fmt.Errorf("week %d matchup has unknown team %s", week+1, name)
+				}
+			}
+		}
+	}
+
+	if l.PlayoffTeams == 0 {
+		l.PlayoffTeams = min(DEFAULT_PLAYOFF_TEAMS, len(l.Teams))
+	}
+	if l.PlayoffTeams < 1 || l.PlayoffTeams > len(l.Teams) {
+		return fmt.Errorf("playoff teams must be between 1 and %d, found %d", len(l.Teams), l.PlayoffTeams)
+	}
+	return nil
+}
+
+// roundRobin schedules every team against every other team in turn, repeating
+// as needed to fill the weeks. With an odd number of teams one team sits out
+// each week.
+func roundRobin(names []string, weeks int) [][][]string {
+	teams := slices.Clone(names)
+	if len(teams)%2 == 1 {
+		teams = append(teams, "")
+	}
+
+	var schedule [][][]string
+	for week := range weeks {
+		// rotate every team but the first around the circle
+		round := week % (len(teams) - 1)
+		rotated := append([]string{teams[0]}, rotate(teams[1:], round)...)
+
+		var matchups [][]string
+		for i := range len(rotated) / 2 {
+			home, away := rotated[i], rotated[len(rotated)-1-i]
+			if home != "" && away != "" {
+				matchups = append(matchups, []string{home, away})
+			}
+		}
+		schedule = append(schedule, matchups)
+	}
+	return schedule
+}
+
+func rotate(teams []string, n int) []string {
+	n = n % len(teams)
+	return append(slices.Clone(teams[len(teams)-n:]), teams[:len(teams)-n]...)
+}
@@ -0,0 +1,120 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"github.com/boldandbrad/fffetch/internal/util"
+)
+
+// SeasonGames returns the games in a year's NFL regular season: 17 since
+// 2021 and 16 before
+func SeasonGames(year int) int {
+	if year >= 2021 {
+		return 17
+	}
+	return 16
+}
+
+// Player is the distribution of a player's weekly fantasy points
+type Player struct {
+	ID   string
+	Name string
+	Pos  string
+	// Weeks are the points scored in each game played
+	Weeks []float64
+	// Availability is the chance the player plays in a given week
+	Availability float64
+	// Mean is the points expected in a game played, used to set lineups
+	Mean float64
+}
+
+// NewPlayers builds each player's weekly points distribution from their game
+// log rows, missing games out of the season's games. Players with no game
+// logs, like team defenses, fall back to scoring their season points per game
+// from the season rows every game.
+func NewPlayers(weekly util.Table, season util.Table, ptsHeader string, seasonGames int) (map[string]*Player, error) {
+	players := map[string]*Player{}
+
+	weeklyMap := weekly.ToMap()
+	if len(weeklyMap.Dicts) > 0 {
+		if err := weeklyMap.Require("pos", ptsHeader); err != nil {
+			return nil, err
+		}
+	}
+	for _, dict := range weeklyMap.Dicts {
+		pts, err := points(dict, ptsHeader)
+		if err != nil {
+			return nil, err
+		}
+		key := util.PlayerKey(dict)
+		player, exists := players[key]
+		if !exists {
+			player = &Player{ID: dict["player_id"], Name: dict["player"], Pos: strings.ToUpper(dict["pos"])}
+			players[key] = player
+		}
+		player.Weeks = append(player.Weeks, pts)
+	}
+	for _, player := range players {
+		total := 0.0
+		for _, pts := range player.Weeks {
+			total += pts
+		}
+		player.Mean = total / float64(len(player.Weeks))
+		player.Availability = min(float64(len(player.Weeks))/float64(seasonGames), 1)
+	}
+
+	seasonMap := season.ToMap()
+	if len(seasonMap.Dicts) > 0 {
+		if err := seasonMap.Require("pos", "g", ptsHeader); err != nil {
+			return nil, err
+		}
+	}
+	for _, dict := range seasonMap.Dicts {
+		key := util.PlayerKey(dict)
+		if _, exists := players[key]; exists {
+			continue
+		}
+		pts, err := points(dict, ptsHeader)
+		if err != nil {
+			return nil, err
+		}
+		games, err := points(dict, "g")
+		if err != nil {
+			return nil, err
+		}
+		if games == 0 {
+			continue
+		}
+		players[key] = &Player{
+			ID:           dict["player_id"],
+			Name:         dict["player"],
+			Pos:          strings.ToUpper(dict["pos"]),
+			Weeks:        []float64{pts / games},
+			Availability: min(games/float64(seasonGames), 1),
+			Mean:         pts / games,
+		}
+	}
+	return players, nil
+}
+
+// Sample draws the player's points for a week, reporting whether they played
+func (p *Player) Sample(rng *rand.Rand) (float64, bool) {
+	if rng.Float64() >= p.Availability {
+		return 0, false
+	}
+	return p.Weeks[rng.IntN(len(p.Weeks))], true
+}
+
+func points(dict map[string]string, header string) (float64, error) {
+	if dict[header] == "" {
+		return 0, nil
+	}
+	val, err := strconv.ParseFloat(dict[header], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s for %s: %w", header, dict["player"], err)
+	}
+	return val, nil
+}
@@ -0,0 +1,203 @@
+package sim
+
+import (
+	"cmp"
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/boldandbrad/fffetch/internal/calc"
+	"github.com/boldandbrad/fffetch/internal/util"
+)
+
+// Result is a team's outcomes averaged over every simulated season
+type Result struct {
+	Team             string
+	Wins             float64
+	PointsFor        float64
+	PlayoffOdds      float64
+	ChampionshipOdds float64
+}
+
+// Simulator plays Monte Carlo seasons of a league
+type Simulator struct {
+	league  League
+	rosters map[string][]*Player
+	rng     *rand.Rand
+}
+
+// NewSimulator matches each team's roster to players' weekly distributions
+func NewSimulator(league League, players map[string]*Player, rng *rand.Rand) (*Simulator, error) {
+	if err := league.Validate(); err != nil {
+		return nil, err
+	}
+
+	byName := map[string][]*Player{}
+	for _, player := range players {
+		name := strings.ToLower(player.Name)
+		byName[name] = append(byName[name], player)
+	}
+
+	rosters := map[string][]*Player{}
+	for _, team := range league.Teams {
+		for _, entry := range team.Roster {
+			if player, exists := players[entry]; exists {
+				rosters[team.Name] = append(rosters[team.Name], player)
+				continue
+			}
+			matches := byName[strings.ToLower(entry)]
+			switch len(matches) {
+			case 0:
+				return nil, fmt.Errorf("no %d data for %s on %s", league.Year, entry, team.Name)
+			case 1:
+				rosters[team.Name] = append(rosters[team.Name], matches[0])
+			default:
+				return nil, fmt.Errorf("more than one player named %s, use a player id for %s", entry, team.Name)
+			}
+		}
+	}
+	return &Simulator{league: league, rosters: rosters, rng: rng}, nil
+}
+
+// Run simulates the given number of seasons, returning each team's results
+// in league order
+func (s *Simulator) Run(seasons int) []Result {
+	results := map[string]*Result{}
+	for _, team := range s.league.Teams {
+		results[team.Name] = &Result{Team: team.Name}
+	}
+
+	for range seasons {
+		wins := map[string]float64{}
+		pointsFor := map[string]float64{}
+		for _, matchups := range s.league.Schedule {
+			for _, matchup := range matchups {
+				home, away := s.score(matchup[0]), s.score(matchup[1])
+				pointsFor[matchup[0]] += home
+				pointsFor[matchup[1]] += away
+				switch {
+				case home > away:
+					wins[matchup[0]] += 1
+				case away > home:
+					wins[matchup[1]] += 1
+				default:
+					wins[matchup[0]] += 0.5
+					wins[matchup[1]] += 0.5
+				}
+			}
+		}
+
+		var seeds []string
+		for _, team := range s.league.Teams {
+			seeds = append(seeds, team.Name)
+		}
+		slices.SortStableFunc(seeds, func(i, j string) int {
+			return cmp.Or(cmp.Compare(wins[j], wins[i]), cmp.Compare(pointsFor[j], pointsFor[i]))
+		})
+		seeds = seeds[:s.league.PlayoffTeams]
+
+		for _, team := range s.league.Teams {
+			results[team.Name].Wins += wins[team.Name]
+			results[team.Name].PointsFor += pointsFor[team.Name]
+		}
+		for _, team := range seeds {
+			results[team].PlayoffOdds += 1
+		}
+		results[s.playoffs(seeds)].ChampionshipOdds += 1
+	}
+
+	var ordered []Result
+	for _, team := range s.league.Teams {
+		result := results[team.Name]
+		result.Wins /= float64(seasons)
+		result.PointsFor /= float64(seasons)
+		result.PlayoffOdds /= float64(seasons)
+		result.ChampionshipOdds /= float64(seasons)
+		ordered = append(ordered, *result)
+	}
+	return ordered
+}
+
+// playoffs plays a single elimination bracket between seeded teams, giving
+// the top seeds byes when the field isn't a power of two, and returns the
+// champion. Ties go to the higher seed.
+func (s *Simulator) playoffs(seeds []string) string {
+	alive := slices.Clone(seeds)
+	for len(alive) > 1 {
+		bracket := 1 << (bits.Len(uint(len(alive))) - 1)
+		games := len(alive) - bracket
+		if games == 0 {
+			games = len(alive) / 2
+		}
+
+		// the lowest seeds play, highest against lowest
+		byes := len(alive) - 2*games
+		next := slices.Clone(alive[:byes])
+		playing := alive[byes:]
+		for i := range games {
+			high, low := playing[i], playing[len(playing)-1-i]
+			if s.score(low) > s.score(high) {
+				next = append(next, low)
+			} else {
+				next = append(next, high)
+			}
+		}
+
+		// keep the survivors in seed order
+		slices.SortStableFunc(next, func(i, j string) int {
+			return cmp.Compare(slices.Index(seeds, i), slices.Index(seeds, j))
+		})
+		alive = next
+	}
+	return alive[0]
+}
+
+// score simulates a team's points for a week, starting the available players
+// with the most expected points in each slot
+func (s *Simulator) score(team string) float64 {
+	type sample struct {
+		player *Player
+		pts    float64
+	}
+	var available []sample
+	for _, player := range s.rosters[team] {
+		if pts, played := player.Sample(s.rng); played {
+			available = append(available, sample{player: player, pts: pts})
+		}
+	}
+	slices.SortStableFunc(available, func(i, j sample) int {
+		return cmp.Compare(j.player.Mean, i.player.Mean)
+	})
+
+	var positions []string
+	for _, sample := range available {
+		positions = append(positions, sample.player.Pos)
+	}
+	total := 0.0
+	for i, started := range calc.FillLineup(positions, s.league.Slots) {
+		if started {
+			total += available[i].pts
+		}
+	}
+	return total
+}
+
+// ResultsTable lays out simulation results for output
+func ResultsTable(results []Result) util.Table {
+	table := util.Table{
+		Name:    "simulation",
+		Headers: []string{"team", "wins", "points_for", "playoff_odds", "championship_odds"},
+	}
+	for _, result := range results {
+		table.Rows = append(table.Rows, []string{
+			result.Team,
+			fmt.Sprintf("%.2f", result.Wins),
+			fmt.Sprintf("%.2f", result.PointsFor),
+			fmt.Sprintf("%.2f%%", result.PlayoffOdds*100),
+			fmt.Sprintf("%.2f%%", result.ChampionshipOdds*100),
+		})
+	}
+	return table
+}
@@ -0,0 +1,201 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/boldandbrad/fffetch/internal/pfr"
+	"github.com/boldandbrad/fffetch/internal/util"
+	_ "modernc.org/sqlite"
+)
+
+// DEFAULT_DB_PATH is where the database is kept unless configured otherwise
+var DEFAULT_DB_PATH = "output/fffetch.db"
+
+// key columns of player_season_stats, kept out of its stat columns
+var KEY_HEADERS = []string{"player_id", "team", "year", "kind"}
+
+// kinds of final data, each kept in its own rows of player_season_stats, since
+// a player can have offense and IDP rows, such as a defender returning kicks
+const (
+	KindOffense = "offense"
+	KindIDP     = "idp"
+	KindDST     = "dst"
+)
+
+// columns held in the players table rather than with each season
+var PLAYER_HEADERS = []string{"player"}
+
+// SQL column types of each column type, matching the JSON and Parquet outputs
+var SQL_TYPES = map[util.ColumnType]string{
+	util.IntColumn:   "INTEGER",
+	util.FloatColumn: "REAL",
+	util.TextColumn:  "TEXT",
+}
+
+var SCHEMA = []string{
+	`CREATE TABLE IF NOT EXISTS players (
+		player_id TEXT PRIMARY KEY,
+		player TEXT NOT NULL,
+		pos TEXT
+	)`,
+	`CREATE TABLE IF NOT EXISTS teams (
+		team TEXT PRIMARY KEY,
+		pfr_key TEXT
+	)`,
+	`CREATE TABLE IF NOT EXISTS seasons (
+		year INTEGER PRIMARY KEY
+	)`,
+	`CREATE TABLE IF NOT EXISTS player_season_stats (
+		player_id TEXT NOT NULL REFERENCES players (player_id),
+		team TEXT NOT NULL REFERENCES teams (team),
+		year INTEGER NOT NULL REFERENCES seasons (year),
+		kind TEXT NOT NULL,
+		PRIMARY KEY (player_id, team, year, kind)
+	)`,
+}
+
+// Store is a SQLite database of players' season stats
+type Store struct {
+	db      *sql.DB
+	columns map[string]bool
+}
+
+// Open opens the database at the given path, creating it and its tables if
+// they don't exist
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// a single connection keeps writes from contending for the file lock
+	db.SetMaxOpenConns(1)
+
+	for _, stmt := range append(SCHEMA, "PRAGMA foreign_keys = ON") {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create %s: %w", path, err)
+		}
+	}
+
+	store := &Store{db: db, columns: map[string]bool{}}
+	rows, err := db.Query("SELECT name FROM pragma_table_info('player_season_stats')")
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			db.Close()
+			return nil, err
+		}
+		store.columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Replace writes one kind of a team's final data for a year, replacing any
+// stats already stored for that team, year and kind, including those of
+// players no longer in the table, and upserting its players. Stat columns the
+// database doesn't have yet, such as a new scoring profile's, are added.
+func (s *Store) Replace(team string, year int, kind string, table util.Table) error {
+	m := table.ToMap()
+	if err := m.Require("player"); err != nil {
+		return err
+	}
+	types := map[string]util.ColumnType{}
+	for i, colType := range util.ColumnTypes(table) {
+		types[m.Headers[i]] = colType
+	}
+	var statHeaders []string
+	for _, header := range m.Headers {
+		if !slices.Contains(KEY_HEADERS, header) && !slices.Contains(PLAYER_HEADERS, header) {
+			statHeaders = append(statHeaders, header)
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, header := range statHeaders {
+		if s.columns[header] {
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE player_season_stats ADD COLUMN %s %s", quote(header), SQL_TYPES[types[header]])); err != nil {
+			return err
+		}
+	}
+
+	columns := append(slices.Clone(KEY_HEADERS), statHeaders...)
+	var quoted []string
+	for _, column := range columns {
+		quoted = append(quoted, quote(column))
+	}
+	statsStmt := fmt.Sprintf("INSERT INTO player_season_stats (%s) VALUES (%s)",
+		strings.Join(quoted, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "))
+
+	if _, err := tx.Exec("INSERT INTO teams (team, pfr_key) VALUES (?, ?) ON CONFLICT (team) DO NOTHING",
+		team, pfr.PFR_TEAM_KEYS[team]); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO seasons (year) VALUES (?) ON CONFLICT (year) DO NOTHING", year); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM player_season_stats WHERE team = ? AND year = ? AND kind = ?", team, year, kind); err != nil {
+		return err
+	}
+
+	for _, dict := range m.Dicts {
+		playerID := util.PlayerKey(dict)
+		pos, err := util.TypedValue(dict["pos"], util.TextColumn)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`INSERT INTO players (player_id, player, pos) VALUES (?, ?, ?)
+			ON CONFLICT (player_id) DO UPDATE SET player = excluded.player, pos = COALESCE(excluded.pos, players.pos)`,
+			playerID, dict["player"], pos); err != nil {
+			return err
+		}
+
+		values := []any{playerID, team, year, kind}
+		for _, header := range statHeaders {
+			val, err := util.TypedValue(dict[header], types[header])
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", header, err)
+			}
+			values = append(values, val)
+		}
+		if _, err := tx.Exec(statsStmt, values...); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, header := range statHeaders {
+		s.columns[header] = true
+	}
+	return nil
+}
+
+// quote quotes a column name, since some, like order, are SQL keywords
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,13 @@
+package util
+
+import "fmt"
+
+// MissingColumnError reports a table lacking a column required to process it
+type MissingColumnError struct {
+	Table  string
+	Column string
+}
+
+func (e *MissingColumnError) Error() string {
+	return fmt.Sprintf("table %s is missing column %s", e.Table, e.Column)
+}
@@ -1,41 +1,124 @@
 package util
 
 import (
+	"bufio"
 	"encoding/csv"
-	"log"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
-var OUT_DIRS = []string{"output", "output/fetched_pages", "output/parsed_tables", "output/final"}
+var OUT_DIRS = []string{"output", "output/fetched_pages", "output/fetched_pages/gamelogs", "output/parsed_tables", "output/final", "output/weekly", "output/league", "output/projections", "output/rankings", "output/simulations", "output/drafts"}
 
-func CreateOutDirs() {
+func CreateOutDirs() error {
 	// create output directories if they don't exist
 	for _, dir := range OUT_DIRS {
 		if _, err := os.Stat(dir); os.IsNotExist(err) {
 			if err := os.Mkdir(dir, 0755); err != nil {
-				log.Fatal(err)
+				return err
 			}
 		}
 	}
+	return nil
 }
 
-func WriteCSVFile(filePath string, table Table) {
-	file, err := os.Create(filePath)
+// WriteCSVFile writes a table as CSV, with any totals as the last row
+func WriteCSVFile(filePath string, table Table) error {
+	lines := append([][]string{table.Headers}, table.Rows...)
+	if len(table.FooterRow) > 0 {
+		lines = append(lines, table.FooterRow)
+	}
+	return writeAtomic(filePath, func(w io.Writer) error {
+		return csv.NewWriter(w).WriteAll(lines)
+	})
+}
+
+// ReadCSVFile reads a table written by WriteCSVFile. A trailing row without a
+// player_id is read back as the totals row.
+func ReadCSVFile(filePath string) (Table, error) {
+	file, err := os.Open(filePath)
 	if err != nil {
-		log.Fatal(err)
+		return Table{}, err
 	}
+	defer file.Close()
 
-	lines := append([][]string{table.Headers}, table.Rows...)
-	lines = append(lines, table.FooterRow)
-	writer := csv.NewWriter(file)
-	err = writer.WriteAll(lines)
+	lines, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return Table{}, err
+	}
+	if len(lines) == 0 {
+		return Table{}, fmt.Errorf("%s is empty", filePath)
+	}
+
+	table := Table{
+		Name:    strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath)),
+		Headers: lines[0],
+		Rows:    lines[1:],
+	}
+	idIndex := slices.Index(table.Headers, "player_id")
+	if last := len(table.Rows) - 1; idIndex >= 0 && last >= 0 && table.Rows[last][idIndex] == "" {
+		table.FooterRow = table.Rows[last]
+		table.Rows = table.Rows[:last]
+	}
+	return table, nil
+}
+
+// WriteFile writes contents to a file, replacing it only once fully written
+func WriteFile(filePath string, contents string) error {
+	return writeAtomic(filePath, func(w io.Writer) error {
+		_, err := io.WriteString(w, contents)
+		return err
+	})
+}
+
+// writeAtomic writes a file by way of a temp file beside it, flushed and
+// synced to disk before it's renamed into place, so an interrupted write never
+// leaves a partial file behind
+func writeAtomic(filePath string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(filePath)
+	file, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".tmp-*")
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	tempPath := file.Name()
+	// a no-op once the temp file has been renamed
+	defer os.Remove(tempPath)
+
+	buffered := bufio.NewWriter(file)
+	if err := write(buffered); err != nil {
+		file.Close()
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(0644); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, filePath); err != nil {
+		return err
 	}
+	syncDir(dir)
+	return nil
 }
 
-func WriteFile(filePath string, contents string) {
-	if err := os.WriteFile(filePath, []byte(contents), 0644); err != nil {
-		log.Fatal(err)
+// syncDir syncs a directory so a rename into it survives a crash. Not every
+// platform can sync a directory, so this is best effort.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
 	}
 }
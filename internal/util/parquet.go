@@ -0,0 +1,70 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Go types each column type is stored as, pointers so blank cells are null
+var parquetTypes = map[ColumnType]reflect.Type{
+	IntColumn:   reflect.TypeFor[*int64](),
+	FloatColumn: reflect.TypeFor[*float64](),
+	TextColumn:  reflect.TypeFor[*string](),
+}
+
+// parquetModel builds a struct type with a field for each column, in order,
+// for parquet-go to derive the file's schema from. Every column is optional,
+// since ages, ranks and projections can be blank.
+func parquetModel(headers []string, types []ColumnType) reflect.Type {
+	var fields []reflect.StructField
+	for i, header := range headers {
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("F%d", i),
+			Type: parquetTypes[types[i]],
+			Tag:  reflect.StructTag(fmt.Sprintf(`parquet:"%s,optional"`, header)),
+		})
+	}
+	return reflect.StructOf(fields)
+}
+
+// WriteParquetFile writes a table's rows to a Parquet file, typing each column
+// as in ColumnTypes: counts as integers, rates and points as floats, and
+// percentages as fractions. The totals row is left out.
+func WriteParquetFile(filePath string, table Table) error {
+	types := ColumnTypes(table)
+	model := parquetModel(table.Headers, types)
+	rows := reflect.MakeSlice(reflect.SliceOf(model), len(table.Rows), len(table.Rows))
+	for i, row := range table.Rows {
+		rowValue := rows.Index(i)
+		for j, header := range table.Headers {
+			if j >= len(row) || row[j] == "" {
+				continue
+			}
+			val, err := TypedValue(row[j], types[j])
+			if err != nil {
+				return fmt.Errorf("%s row %d: invalid %s: %w", table.Name, i+1, header, err)
+			}
+			ptr := reflect.New(parquetTypes[types[j]].Elem())
+			ptr.Elem().Set(reflect.ValueOf(val))
+			rowValue.Field(j).Set(ptr)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return writeAtomic(filePath, func(w io.Writer) error {
+		writer := parquet.NewWriter(w, parquet.SchemaOf(reflect.New(model).Interface()))
+		for i := range rows.Len() {
+			if err := writer.Write(rows.Index(i).Addr().Interface()); err != nil {
+				return err
+			}
+		}
+		return writer.Close()
+	})
+}
@@ -3,16 +3,18 @@ package util
 import (
 	"cmp"
 	"fmt"
-	"log"
 	"slices"
 	"strconv"
+	"strings"
 )
 
 var FINAL_HEADERS = []string{
 	"year",
+	"team",
 	"order",
 	"projection",
 	"player",
+	"player_id",
 	"age",
 	"pos",
 	"g",
@@ -39,6 +41,29 @@ var FINAL_HEADERS = []string{
 	"pass_long",
 	"rush_long",
 	"rec_long",
+	"fga",
+	"fgm",
+	"fg_missed",
+	"fga_0_19",
+	"fgm_0_19",
+	"fga_20_29",
+	"fgm_20_29",
+	"fga_30_39",
+	"fgm_30_39",
+	"fga_40_49",
+	"fgm_40_49",
+	"fga_50_plus",
+	"fgm_50_plus",
+	"fg_long",
+	"xpa",
+	"xpm",
+	"xp_missed",
+	"kick_ret",
+	"kick_ret_yds",
+	"kick_ret_td",
+	"punt_ret",
+	"punt_ret_yds",
+	"punt_ret_td",
 	"pass_cmp%",
 	"pass_att%",
 	"pass_yds%",
@@ -58,15 +83,130 @@ var FINAL_HEADERS = []string{
 	"rec_1d%",
 	"touches%",
 	"fumbles%",
-	"std_pts",
-	"half_ppr_pts",
-	"ppr_pts",
-	"std_ppg",
-	"half_ppr_ppg",
-	"ppr_ppg",
-	"pos_rank",
 }
 
+var FINAL_IDP_HEADERS = []string{
+	"year",
+	"team",
+	"order",
+	"player",
+	"player_id",
+	"age",
+	"pos",
+	"g",
+	"gs",
+	"tackles_combined",
+	"tackles_solo",
+	"tackles_assists",
+	"tackles_loss",
+	"sacks",
+	"qb_hits",
+	"pass_defended",
+	"def_int",
+	"def_int_yds",
+	"def_int_td",
+	"fumbles_forced",
+	"fumbles_rec",
+	"fumbles_rec_yds",
+	"fumbles_rec_td",
+	"safety_md",
+}
+
+var FINAL_DST_HEADERS = []string{
+	"year",
+	"team",
+	"order",
+	"player",
+	"player_id",
+	"pos",
+	"g",
+	"pts_allowed",
+	"pa_0",
+	"pa_1_6",
+	"pa_7_13",
+	"pa_14_20",
+	"pa_21_27",
+	"pa_28_34",
+	"pa_35_plus",
+	"sacks",
+	"def_int",
+	"fumbles_rec",
+	"def_td",
+	"ret_td",
+	"safety",
+}
+
+var FINAL_WEEKLY_HEADERS = []string{
+	"year",
+	"week",
+	"game_date",
+	"player",
+	"player_id",
+	"pos",
+	"team",
+	"opp",
+	"home_away",
+	"result",
+	"rush_att",
+	"rush_yds",
+	"rush_td",
+	"targets",
+	"rec",
+	"rec_yds",
+	"rec_td",
+	"fumbles",
+	"pass_cmp",
+	"pass_att",
+	"pass_yds",
+	"pass_td",
+	"pass_int",
+	"times sacked",
+	"fga",
+	"fgm",
+	"fg_missed",
+	"xpa",
+	"xpm",
+	"xp_missed",
+	"kick_ret",
+	"kick_ret_yds",
+	"kick_ret_td",
+	"punt_ret",
+	"punt_ret_yds",
+	"punt_ret_td",
+}
+
+// FinalHeaders returns FINAL_HEADERS followed by the given fantasy football
+// score and rank headers.
+func FinalHeaders(scoreHeaders []string) []string {
+	return finalHeaders(FINAL_HEADERS, scoreHeaders)
+}
+
+// FinalIDPHeaders returns FINAL_IDP_HEADERS followed by the given fantasy
+// football score and rank headers.
+func FinalIDPHeaders(scoreHeaders []string) []string {
+	return finalHeaders(FINAL_IDP_HEADERS, scoreHeaders)
+}
+
+// FinalDSTHeaders returns FINAL_DST_HEADERS followed by the given fantasy
+// football score and rank headers.
+func FinalDSTHeaders(scoreHeaders []string) []string {
+	return finalHeaders(FINAL_DST_HEADERS, scoreHeaders)
+}
+
+// FinalWeeklyHeaders returns FINAL_WEEKLY_HEADERS followed by the given
+// fantasy football points headers.
+func FinalWeeklyHeaders(ptsHeaders []string) []string {
+	return append(slices.Clone(FINAL_WEEKLY_HEADERS), ptsHeaders...)
+}
+
+func finalHeaders(baseHeaders []string, scoreHeaders []string) []string {
+	return append(slices.Clone(baseHeaders), scoreHeaders...)
+}
+
+// headers left blank rather than zeroed when a row has no value, along with
+// any rank header
+var BLANK_HEADERS = []string{"order", "projection", "age"}
+
 type Table struct {
 	Name      string
 	Headers   []string
@@ -74,6 +214,14 @@ type Table struct {
 	FooterRow []string
 }
 
+// GameLog is one player's per-week stats for a season
+type GameLog struct {
+	Player   string
+	PlayerID string
+	Pos      string
+	Table
+}
+
 type TableMap struct {
 	Name       string
 	Headers    []string
@@ -91,18 +239,32 @@ func (t Table) ToMap() TableMap {
 	for _, row := range t.Rows {
 		rowDict := map[string]string{}
 		for i, header := range t.Headers {
-			rowDict[header] = row[i]
+			if i < len(row) {
+				rowDict[header] = row[i]
+			}
 		}
 		rowDicts = append(rowDicts, rowDict)
 	}
 	tableMap.Dicts = rowDicts
 	// convert footer row to dict
 	for i, header := range t.Headers {
-		tableMap.FooterDict[header] = t.FooterRow[i]
+		if i < len(t.FooterRow) {
+			tableMap.FooterDict[header] = t.FooterRow[i]
+		}
 	}
 	return tableMap
 }
 
+// Require returns a MissingColumnError for the first header not in the table
+func (m TableMap) Require(headers ...string) error {
+	for _, header := range headers {
+		if !slices.Contains(m.Headers, header) {
+			return &MissingColumnError{Table: m.Name, Column: header}
+		}
+	}
+	return nil
+}
+
 func (m TableMap) ToTable() Table {
 	var table Table
 	table.Name = m.Name
@@ -114,7 +276,7 @@ func (m TableMap) ToTable() Table {
 			value, exists := dict[header]
 			if exists && value != "" {
 				row = append(row, value)
-			} else if header == "projection" || header == "pos_rank" {
+			} else if slices.Contains(BLANK_HEADERS, header) || strings.HasSuffix(header, "_rank") {
 				row = append(row, "")
 			} else {
 				row = append(row, "0")
@@ -122,7 +284,10 @@ func (m TableMap) ToTable() Table {
 		}
 		table.Rows = append(table.Rows, row)
 	}
-	// convert footer dict to table row
+	// convert footer dict to table row, if the table has one
+	if len(m.FooterDict) == 0 {
+		return table
+	}
 	for _, header := range m.Headers {
 		value, exists := m.FooterDict[header]
 		if exists {
@@ -134,23 +299,40 @@ func (m TableMap) ToTable() Table {
 	return table
 }
 
-func (t Table) Sort() Table {
+func (t Table) Sort(ptsHeader string) (Table, error) {
 	m := t.ToMap()
+	if err := m.Require("pos", ptsHeader); err != nil {
+		return Table{}, err
+	}
 
-	// sort by position then std_pts
-	slices.SortFunc(m.Dicts, func(i, j map[string]string) int {
-		val1, err := strconv.ParseFloat(i["std_pts"], 64)
-		val2, err := strconv.ParseFloat(j["std_pts"], 64)
+	// parse points up front so sorting can't fail midway
+	pts := make(map[string]float64, len(m.Dicts))
+	for _, dict := range m.Dicts {
+		val, err := strconv.ParseFloat(dict[ptsHeader], 64)
 		if err != nil {
-			log.Fatal(err)
+			return Table{}, fmt.Errorf("invalid %s for %s: %w", ptsHeader, dict["player"], err)
 		}
+		pts[PlayerKey(dict)] = val
+	}
+
+	// sort by position then points
+	slices.SortFunc(m.Dicts, func(i, j map[string]string) int {
 		return cmp.Or(
 			cmp.Compare(i["pos"], j["pos"]),
-			cmp.Compare(val1, val2)*-1,
+			cmp.Compare(pts[PlayerKey(i)], pts[PlayerKey(j)])*-1,
 		)
 	})
 
-	return m.ToTable()
+	return m.ToTable(), nil
+}
+
+// PlayerKey identifies a player record by its player id, falling back to the
+// player's name for records without one
+func PlayerKey(dict map[string]string) string {
+	if id := dict["player_id"]; id != "" {
+		return id
+	}
+	return dict["player"]
 }
 
 func MergeTables(tables []Table) Table {
@@ -167,16 +349,16 @@ func MergeTables(tables []Table) Table {
 					mergedTableMap.Headers = append(mergedTableMap.Headers, header)
 				}
 			}
-			// check if record already exists by player name
+			// check if record already exists by player
 			tblMap := tbl.ToMap()
 			if len(tblMap.Dicts) > 0 {
 				for _, record := range tblMap.Dicts {
-					recordName := record["player"]
+					recordKey := PlayerKey(record)
 					recordFound := false
 
 					for _, mergedRecord := range mergedTableMap.Dicts {
 						// if so, append row data to that record
-						if mergedRecord["player"] == recordName {
+						if PlayerKey(mergedRecord) == recordKey {
 							recordFound = true
 							for _, header := range tbl.Headers {
 								mergedRecord[header] = record[header]
@@ -205,21 +387,84 @@ func MergeTables(tables []Table) Table {
 	return mergedTable
 }
 
-func (t Table) PruneColumns() Table {
+// MergeGameLogs combines players' game logs into one table with a row per
+// player per game played for a team, given by its abbreviation in the logs.
+// A traded player's log also holds their games for other teams, which are
+// left out.
+func MergeGameLogs(logs []GameLog, teamAbbr string) Table {
+	var mergedTableMap TableMap
+	mergedTableMap.Name = "weekly"
+	mergedTableMap.Headers = []string{"player", "player_id", "pos", "g", "home_away"}
+
+	for _, gameLog := range logs {
+		// append new headers
+		for _, header := range gameLog.Headers {
+			if !slices.Contains(mergedTableMap.Headers, header) {
+				mergedTableMap.Headers = append(mergedTableMap.Headers, header)
+			}
+		}
+
+		for _, record := range gameLog.ToMap().Dicts {
+			if record["team"] != "" && record["team"] != teamAbbr {
+				continue
+			}
+			record["player"] = gameLog.Player
+			record["player_id"] = gameLog.PlayerID
+			record["pos"] = gameLog.Pos
+			record["g"] = "1"
+			switch record["game_location"] {
+			case "@":
+				record["home_away"] = "away"
+			case "N":
+				record["home_away"] = "neutral"
+			default:
+				record["home_away"] = "home"
+			}
+			mergedTableMap.Dicts = append(mergedTableMap.Dicts, record)
+		}
+	}
+	return mergedTableMap.ToTable()
+}
+
+// ConcatTables stacks the rows of tables with any of the same headers
+func ConcatTables(name string, tables []Table) Table {
+	var concatTableMap TableMap
+	concatTableMap.Name = name
+
+	for _, tbl := range tables {
+		// append new headers
+		for _, header := range tbl.Headers {
+			if !slices.Contains(concatTableMap.Headers, header) {
+				concatTableMap.Headers = append(concatTableMap.Headers, header)
+			}
+		}
+		concatTableMap.Dicts = append(concatTableMap.Dicts, tbl.ToMap().Dicts...)
+	}
+	return concatTableMap.ToTable()
+}
+
+func (t Table) PruneColumns(headers []string) Table {
 	tableMap := t.ToMap()
-	tableMap.Headers = FINAL_HEADERS
+	tableMap.Headers = headers
 	prunedTable := tableMap.ToTable()
 	return prunedTable
 }
 
 func (t Table) AddTeamAndYear(team string, year string) Table {
 	tableMap := t.ToMap()
-	tableMap.Headers = append(tableMap.Headers, "year")
+	for _, header := range []string{"team", "year"} {
+		if !slices.Contains(tableMap.Headers, header) {
+			tableMap.Headers = append(tableMap.Headers, header)
+		}
+	}
 	for _, dict := range tableMap.Dicts {
+		dict["team"] = team
 		dict["year"] = year
 	}
-	tableMap.FooterDict["year"] = year
-	tableMap.FooterDict["player"] = fmt.Sprintf("%s Totals", team)
+	if len(tableMap.FooterDict) > 0 {
+		tableMap.FooterDict["year"] = year
+		tableMap.FooterDict["player"] = fmt.Sprintf("%s Totals", team)
+	}
 
 	return tableMap.ToTable()
 }
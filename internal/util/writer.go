@@ -0,0 +1,272 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Writer writes tables to files in an output format
+type Writer interface {
+	// Ext is the file extension of the format, e.g. ".csv"
+	Ext() string
+	Write(filePath string, table Table) error
+}
+
+// WRITERS are the supported output formats, by name
+var WRITERS = map[string]Writer{
+	"csv":    CSVWriter{},
+	"json":   JSONWriter{},
+	"ndjson": NDJSONWriter{},
+}
+
+// NewWriters looks up the writers for each named format
+func NewWriters(formats []string) ([]Writer, error) {
+	var writers []Writer
+	for _, format := range formats {
+		writer, exists := WRITERS[strings.ToLower(format)]
+		if !exists {
+			return nil, fmt.Errorf("unknown output format: %s", format)
+		}
+		writers = append(writers, writer)
+	}
+	return writers, nil
+}
+
+// WriteTable writes a table with each writer, replacing the extension of the
+// given path with each format's own
+func WriteTable(filePath string, table Table, writers []Writer) error {
+	basePath := strings.TrimSuffix(filePath, filepath.Ext(filePath))
+	for _, writer := range writers {
+		if err := writer.Write(basePath+writer.Ext(), table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CSVWriter writes tables as CSV, with any totals as the last row
+type CSVWriter struct{}
+
+func (CSVWriter) Ext() string {
+	return ".csv"
+}
+
+func (CSVWriter) Write(filePath string, table Table) error {
+	return WriteCSVFile(filePath, table)
+}
+
+// JSONWriter writes tables as a JSON object holding the rows and, separately,
+// the totals
+type JSONWriter struct{}
+
+func (JSONWriter) Ext() string {
+	return ".json"
+}
+
+func (JSONWriter) Write(filePath string, table Table) error {
+	types := ColumnTypes(table)
+	var buf bytes.Buffer
+	buf.WriteString(`{"rows":[`)
+	for i, row := range table.Rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		if err := writeJSONRow(&buf, table.Headers, types, row); err != nil {
+			return err
+		}
+	}
+	buf.WriteString(`],"totals":`)
+	if len(table.FooterRow) > 0 {
+		if err := writeJSONRow(&buf, table.Headers, types, table.FooterRow); err != nil {
+			return err
+		}
+	} else {
+		buf.WriteString("null")
+	}
+	buf.WriteString("}")
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		return err
+	}
+	indented.WriteString("\n")
+	return WriteFile(filePath, indented.String())
+}
+
+// NDJSONWriter writes tables as one JSON object per row, so every line has
+// the same shape. Any totals are written the same way to a separate
+// <name>_totals.ndjson file.
+type NDJSONWriter struct{}
+
+func (NDJSONWriter) Ext() string {
+	return ".ndjson"
+}
+
+func (w NDJSONWriter) Write(filePath string, table Table) error {
+	types := ColumnTypes(table)
+	var buf bytes.Buffer
+	for _, row := range table.Rows {
+		if err := writeJSONRow(&buf, table.Headers, types, row); err != nil {
+			return err
+		}
+		buf.WriteString("\n")
+	}
+	if err := WriteFile(filePath, buf.String()); err != nil {
+		return err
+	}
+	if len(table.FooterRow) == 0 {
+		return nil
+	}
+
+	buf.Reset()
+	if err := writeJSONRow(&buf, table.Headers, types, table.FooterRow); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	return WriteFile(strings.TrimSuffix(filePath, w.Ext())+"_totals"+w.Ext(), buf.String())
+}
+
+// writeJSONRow writes a row as an object keyed by header, in header order,
+// typing each value by its column
+func writeJSONRow(buf *bytes.Buffer, headers []string, types []ColumnType, row []string) error {
+	buf.WriteString("{")
+	for i, header := range headers {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, err := json.Marshal(header)
+		if err != nil {
+			return err
+		}
+		value := ""
+		if i < len(row) {
+			value = row[i]
+		}
+		typed, err := TypedValue(value, types[i])
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", header, err)
+		}
+		if f, isFloat := typed.(float64); isFloat {
+			typed = jsonFloat(f)
+		}
+		val, err := json.Marshal(typed)
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(val)
+	}
+	buf.WriteString("}")
+	return nil
+}
+
+// jsonFloat is a float written with a decimal point even when whole, so
+// readers type a float column the same on every row
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	val := strconv.AppendFloat(nil, float64(f), 'f', -1, 64)
+	if !bytes.ContainsRune(val, '.') {
+		val = append(val, ".0"...)
+	}
+	return val, nil
+}
+
+// ColumnType is the type a column's values are written as in typed formats
+type ColumnType int
+
+const (
+	IntColumn ColumnType = iota
+	FloatColumn
+	TextColumn
+)
+
+// headers holding text rather than numbers
+var TEXT_HEADERS = []string{"team", "player", "player_id", "pos", "opp", "home_away", "result", "game_date", "strategy"}
+
+// numeric headers that can hold fractions, along with any percentage, points,
+// points per game or value over replacement header
+var FLOAT_HEADERS = []string{"projection", "sacks"}
+
+// HeaderType is the type a header's values are written as: text, fractions or
+// counts
+func HeaderType(header string) ColumnType {
+	switch {
+	case slices.Contains(TEXT_HEADERS, header):
+		return TextColumn
+	case slices.Contains(FLOAT_HEADERS, header),
+		strings.HasSuffix(header, "%"),
+		strings.HasSuffix(header, "_pts"),
+		strings.HasSuffix(header, "_ppg"),
+		strings.HasSuffix(header, "_vor"):
+		return FloatColumn
+	}
+	return IntColumn
+}
+
+// ColumnTypes returns the type of each of a table's columns, so a column is
+// typed the same on every row. Types come from the headers, except that a
+// count column holding fractions, such as a projected count, is a float column
+// and any other column holding text is a text column.
+func ColumnTypes(table Table) []ColumnType {
+	types := make([]ColumnType, len(table.Headers))
+	for i, header := range table.Headers {
+		types[i] = HeaderType(header)
+		for _, row := range slices.Concat(table.Rows, [][]string{table.FooterRow}) {
+			if types[i] == TextColumn {
+				break
+			}
+			if i >= len(row) || row[i] == "" {
+				continue
+			}
+			if _, err := TypedValue(row[i], types[i]); err == nil {
+				continue
+			}
+			if _, err := TypedValue(row[i], FloatColumn); err == nil {
+				types[i] = FloatColumn
+			} else {
+				types[i] = TextColumn
+			}
+		}
+	}
+	return types
+}
+
+// TypedValue converts a cell to its column's type: an int, a float with
+// percentages as fractions, or text. Blank cells are nil.
+func TypedValue(value string, colType ColumnType) (any, error) {
+	if value == "" {
+		return nil, nil
+	}
+	switch colType {
+	case TextColumn:
+		return value, nil
+	case FloatColumn:
+		if percent, isPercent := strings.CutSuffix(value, "%"); isPercent {
+			// shift the exponent rather than divide, so 12.34% is exactly 0.1234
+			value = percent + "e-2"
+		}
+		val, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		if !isFinite(val) {
+			return nil, fmt.Errorf("not a finite number: %s", value)
+		}
+		return val, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// isFinite reports whether a parsed float can be written as a JSON number,
+// which rules out cells like "NaN" and "Inf"
+func isFinite(val float64) bool {
+	return !math.IsNaN(val) && !math.IsInf(val, 0)
+}
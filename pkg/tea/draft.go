@@ -0,0 +1,265 @@
+package tea
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
+)
+
+// ErrDraftQuit is returned when the user quits the draft
+var ErrDraftQuit = errors.New("draft quit")
+
+// DraftPrompt asks the user to choose a player, or to bid on one
+type DraftPrompt struct {
+	Title string
+	// Status lines are shown above the prompt, like recent picks
+	Status []string
+	// Options are the players to choose from, best first
+	Options []string
+	// Bid asks for a dollar amount rather than one of the options
+	Bid bool
+}
+
+type draftModel struct {
+	prompt   *DraftPrompt
+	answerCh chan int
+	cursor   int
+	filter   string
+	bid      string
+	quitting bool
+}
+
+type promptMsg DraftPrompt
+
+// DraftProgram asks the user for their picks during a draft
+type DraftProgram struct {
+	answerCh    chan int
+	programDone chan struct{}
+	simpleMode  bool
+	program     *tea.Program
+	reader      *bufio.Reader
+}
+
+func NewDraftProgram() *DraftProgram {
+	p := &DraftProgram{
+		answerCh:    make(chan int, 1),
+		programDone: make(chan struct{}),
+		simpleMode:  !isatty.IsTerminal(os.Stderr.Fd()),
+		reader:      bufio.NewReader(os.Stdin),
+	}
+
+	if !p.simpleMode {
+		opts := []tea.ProgramOption{
+			tea.WithOutput(os.Stderr),
+			tea.WithInput(os.Stdin),
+		}
+		p.program = tea.NewProgram(draftModel{answerCh: p.answerCh}, opts...)
+
+		go func() {
+			if _, err := p.program.Run(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			close(p.programDone)
+		}()
+	}
+
+	return p
+}
+
+// Ask prompts the user and waits for their choice, returning the index of the
+// chosen option or the amount bid
+func (p *DraftProgram) Ask(prompt DraftPrompt) (int, error) {
+	if p.simpleMode {
+		return p.askSimple(prompt)
+	}
+	p.program.Send(promptMsg(prompt))
+	select {
+	case answer := <-p.answerCh:
+		return answer, nil
+	case <-p.programDone:
+		return 0, ErrDraftQuit
+	}
+}
+
+func (p *DraftProgram) askSimple(prompt DraftPrompt) (int, error) {
+	fmt.Fprintln(os.Stderr)
+	for _, line := range prompt.Status {
+		fmt.Fprintf(os.Stderr, "  %s\n", line)
+	}
+	fmt.Fprintf(os.Stderr, "\n  %s\n", prompt.Title)
+	if !prompt.Bid {
+		for i, option := range prompt.Options[:min(len(prompt.Options), 20)] {
+			fmt.Fprintf(os.Stderr, "  %3d. %s\n", i+1, option)
+		}
+	}
+
+	for {
+		if prompt.Bid {
+			fmt.Fprint(os.Stderr, "  Max bid (q to quit): ")
+		} else {
+			fmt.Fprint(os.Stderr, "  Pick # (q to quit): ")
+		}
+		line, err := p.reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "q" || (err != nil && line == "") {
+			return 0, ErrDraftQuit
+		}
+		answer, convErr := strconv.Atoi(line)
+		if convErr != nil || answer < 0 {
+			continue
+		}
+		if prompt.Bid {
+			return answer, nil
+		}
+		if answer >= 1 && answer <= len(prompt.Options) {
+			return answer - 1, nil
+		}
+	}
+}
+
+func (p *DraftProgram) Quit() {
+	if !p.simpleMode {
+		p.program.Quit()
+		<-p.programDone
+	}
+}
+
+func (m draftModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m draftModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case promptMsg:
+		prompt := DraftPrompt(msg)
+		m.prompt = &prompt
+		m.cursor, m.filter, m.bid = 0, "", ""
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if m.prompt == nil {
+			return m, nil
+		}
+		if m.prompt.Bid {
+			return m.updateBid(msg)
+		}
+		return m.updatePick(msg)
+	}
+	return m, nil
+}
+
+func (m draftModel) updateBid(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		if bid, err := strconv.Atoi(m.bid); err == nil {
+			m.answerCh <- bid
+			m.prompt = nil
+		}
+	case tea.KeyBackspace:
+		if len(m.bid) > 0 {
+			m.bid = m.bid[:len(m.bid)-1]
+		}
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if r >= '0' && r <= '9' {
+				m.bid += string(r)
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m draftModel) updatePick(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	matches := m.matches()
+	switch msg.Type {
+	case tea.KeyUp:
+		m.cursor = max(m.cursor-1, 0)
+	case tea.KeyDown:
+		m.cursor = min(m.cursor+1, max(len(matches)-1, 0))
+	case tea.KeyEnter:
+		if len(matches) > 0 {
+			m.answerCh <- matches[m.cursor]
+			m.prompt = nil
+		}
+	case tea.KeyBackspace:
+		if len(m.filter) > 0 {
+			m.filter = m.filter[:len(m.filter)-1]
+			m.cursor = 0
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+		m.cursor = 0
+	}
+	return m, nil
+}
+
+// matches returns the indexes of the options containing the filter
+func (m draftModel) matches() []int {
+	var matches []int
+	for i, option := range m.prompt.Options {
+		if strings.Contains(strings.ToLower(option), strings.ToLower(m.filter)) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+func (m draftModel) View() string {
+	if m.quitting {
+		return ""
+	}
+	title := titleStyle.Render("🏈 Fantasy Football Draft")
+	if m.prompt == nil {
+		return fmt.Sprintf("\n%s\n\n  %s\n", title, skipStyle.Render("Waiting for the other teams..."))
+	}
+
+	var statusLines []string
+	for _, line := range m.prompt.Status {
+		statusLines = append(statusLines, skipStyle.Render(line))
+	}
+	status := strings.Join(statusLines, "\n  ")
+
+	if m.prompt.Bid {
+		return fmt.Sprintf(
+			"\n%s\n\n  %s\n\n%s\n\n  Max bid: $%s\n\n  %s\n",
+			title,
+			status,
+			progressStyle.Render(m.prompt.Title),
+			m.bid,
+			skipStyle.Render("enter to bid, 0 to pass, esc to quit"),
+		)
+	}
+
+	// show a window of the matching options around the cursor
+	matches := m.matches()
+	start := max(min(m.cursor-5, len(matches)-10), 0)
+	var optionLines []string
+	for i := start; i < min(start+10, len(matches)); i++ {
+		option := m.prompt.Options[matches[i]]
+		if i == m.cursor {
+			optionLines = append(optionLines, successStyle.Render("> "+option))
+		} else {
+			optionLines = append(optionLines, "  "+option)
+		}
+	}
+
+	return fmt.Sprintf(
+		"\n%s\n\n  %s\n\n%s\n\n  Search: %s\n\n  %s\n\n  %s\n",
+		title,
+		status,
+		progressStyle.Render(m.prompt.Title),
+		m.filter,
+		strings.Join(optionLines, "\n  "),
+		skipStyle.Render("type to search, ↑/↓ to move, enter to pick, esc to quit"),
+	)
+}
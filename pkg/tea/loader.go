@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -14,27 +16,45 @@ type TaskResult struct {
 	Team    string
 	Year    int
 	Success bool
+	Err     error
+}
+
+// RetryWait describes a task waiting to retry a failed request
+type RetryWait struct {
+	Team    string
+	Year    int
+	Attempt int
+	Wait    time.Duration
+	Err     error
 }
 
 type model struct {
+	cancel     func()
 	totalTasks int
 	completed  int
 	quitting   bool
 	taskCh     chan TaskResult
 	lastTasks  []string
+	waiting    string
 }
 
 type taskMsg TaskResult
 
+type waitMsg RetryWait
+
 type Program struct {
 	taskCh      chan TaskResult
 	programDone chan struct{}
 	simpleMode  bool
 	program     *tea.Program
 	firstTask   bool
+	// guards simple mode output, which tasks can report to concurrently
+	mu sync.Mutex
 }
 
-func NewProgram(totalTasks int) *Program {
+// NewProgram creates a progress display for totalTasks tasks. Quitting it
+// with q or ctrl+c calls cancel, so the tasks can stop.
+func NewProgram(totalTasks int, cancel func()) *Program {
 	p := &Program{
 		taskCh:      make(chan TaskResult),
 		programDone: make(chan struct{}),
@@ -48,6 +68,7 @@ func NewProgram(totalTasks int) *Program {
 			tea.WithInput(os.Stdin),
 		}
 		p.program = tea.NewProgram(model{
+			cancel:     cancel,
 			totalTasks: totalTasks,
 			taskCh:     p.taskCh,
 			lastTasks:  []string{},
@@ -76,7 +97,11 @@ func (m model) Init() tea.Cmd {
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case waitMsg:
+		m.waiting = formatWait(RetryWait(msg))
+		return m, nil
 	case taskMsg:
+		m.waiting = ""
 		m.completed++
 		m.lastTasks = append(m.lastTasks, formatTask(TaskResult(msg)))
 		if len(m.lastTasks) > 5 {
@@ -97,6 +122,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		if msg.String() == "q" || msg.String() == "ctrl+c" {
 			m.quitting = true
+			if m.cancel != nil {
+				m.cancel()
+			}
 			return m, tea.Quit
 		}
 	}
@@ -114,10 +142,15 @@ func (m model) View() string {
 	for _, task := range m.lastTasks {
 		if strings.HasPrefix(task, "✅") {
 			taskLines = append(taskLines, successStyle.Render(task))
+		} else if strings.HasPrefix(task, "❌") {
+			taskLines = append(taskLines, failStyle.Render(task))
 		} else {
 			taskLines = append(taskLines, skipStyle.Render(task))
 		}
 	}
+	if m.waiting != "" {
+		taskLines = append(taskLines, waitStyle.Render(m.waiting))
+	}
 	tasks := strings.Join(taskLines, "\n  ")
 
 	return fmt.Sprintf(
@@ -139,13 +172,34 @@ func (p *Program) Start() {
 
 func (p *Program) Update(result TaskResult) {
 	if p.simpleMode {
+		p.mu.Lock()
+		defer p.mu.Unlock()
 		if p.firstTask {
 			fmt.Fprint(os.Stderr, "\n\n")
 			p.firstTask = false
 		}
 		fmt.Fprintf(os.Stderr, "  %s\n", formatTask(result))
+		return
+	}
+	// the display stops reading once it has been quit
+	select {
+	case p.taskCh <- result:
+	case <-p.programDone:
+	}
+}
+
+// Waiting reports that a task is backing off before retrying a request
+func (p *Program) Waiting(wait RetryWait) {
+	if p.simpleMode {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.firstTask {
+			fmt.Fprint(os.Stderr, "\n\n")
+			p.firstTask = false
+		}
+		fmt.Fprintf(os.Stderr, "  %s\n", formatWait(wait))
 	} else {
-		p.taskCh <- result
+		p.program.Send(waitMsg(wait))
 	}
 }
 
@@ -159,6 +213,9 @@ func (p *Program) Quit() {
 }
 
 func formatTask(tr TaskResult) string {
+	if tr.Err != nil {
+		return fmt.Sprintf("❌ %s %d: %v", tr.Team, tr.Year, tr.Err)
+	}
 	status := "✅"
 	if !tr.Success {
 		status = "⏭️"
@@ -166,6 +223,10 @@ func formatTask(tr TaskResult) string {
 	return fmt.Sprintf("%s %s %d", status, tr.Team, tr.Year)
 }
 
+func formatWait(rw RetryWait) string {
+	return fmt.Sprintf("⏳ %s %d: retry %d in %s (%v)", rw.Team, rw.Year, rw.Attempt, rw.Wait.Round(time.Second), rw.Err)
+}
+
 func createProgressBar(completed, total int) string {
 	if total == 0 {
 		return strings.Repeat("░", 30)
@@ -181,4 +242,6 @@ var (
 	progressStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("86"))
 	successStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("70"))
 	skipStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	failStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	waitStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
 )